@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	// The access log is rotated once it reaches accessLogMaxSize megabytes,
+	// keeping accessLogBackups old files.
+	accessLogMaxSize = 100
+	accessLogBackups = 5
+	// accessLogFlush is how often the buffered lines are written to the file.
+	accessLogFlush = time.Second
+)
+
+// Results of the sessions in the access log.
+const (
+	resultOK          = "ok"
+	resultDenied      = "denied"
+	resultRateLimited = "rate_limited"
+	resultCapacity    = "capacity"
+	resultIPCapacity  = "ip_capacity"
+	resultIdle        = "idle"
+	resultMaxDuration = "max_duration"
+	resultMaintenance = "maintenance"
+)
+
+type resultKey struct{}
+
+// setResult records how session s ended, for the access log.
+func setResult(s ssh.Session, result string) {
+	s.Context().SetValue(resultKey{}, result)
+}
+
+// accessLog writes a JSON line per session to a file rotated by size,
+// besides the logs on stdout. Lines are buffered and flushed every
+// accessLogFlush.
+type accessLog struct {
+	file   *lumberjack.Logger
+	logger *log.Logger
+
+	mu   sync.Mutex
+	buf  *bufio.Writer
+	done chan struct{}
+}
+
+func newAccessLog(path string) *accessLog {
+	l := &accessLog{
+		file: &lumberjack.Logger{Filename: path, MaxSize: accessLogMaxSize, MaxBackups: accessLogBackups},
+		done: make(chan struct{}),
+	}
+	l.buf = bufio.NewWriter(l.file)
+	l.logger = log.NewWithOptions(l, log.Options{
+		ReportTimestamp: true,
+		TimeFormat:      time.RFC3339,
+		Formatter:       log.JSONFormatter,
+	})
+	go func() {
+		t := time.NewTicker(accessLogFlush)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				l.flush()
+			case <-l.done:
+				return
+			}
+		}
+	}()
+	return l
+}
+
+func (l *accessLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+func (l *accessLog) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.buf.Flush(); err != nil {
+		log.Error("Could not write access log", "path", l.file.Filename, "error", err)
+	}
+}
+
+// reopen writes the lines left and closes the file, the next line opening
+// it again at its path. This picks up a file moved away by an external log
+// rotation, lumberjack's own rotation reopens it by itself.
+func (l *accessLog) reopen() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.buf.Flush(); err != nil {
+		return err
+	}
+	return l.file.Close()
+}
+
+// Close writes the lines left and closes the file, once the sessions are
+// over.
+func (l *accessLog) Close() error {
+	close(l.done)
+	return l.reopen()
+}
+
+// accessLogMiddleware logs every session to l once it ended, with the result
+// set by the middleware which turned it away or cut it short.
+func accessLogMiddleware(l *accessLog) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			start := time.Now()
+			next(s)
+			result, _ := s.Context().Value(resultKey{}).(string)
+			if result == "" {
+				result = resultOK
+			}
+			l.logger.Info("session",
+				"ip", remoteIP(s),
+				"user", s.User(),
+				"fingerprint", fingerprint(s),
+				"session", sessionID(s),
+				"duration", time.Since(start).Round(time.Millisecond).String(),
+				"result", result,
+			)
+		}
+	}
+}
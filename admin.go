@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+)
+
+const (
+	adminMaxMessage = 140
+	// messageTimeout is how long messages from admins stay on screen.
+	messageTimeout = 10 * time.Second
+	// adminAll is the recipient of messages sent to every session.
+	adminAll = "*"
+)
+
+// adminMsg is a message from an admin, shown to the visitor.
+type adminMsg struct{ text string }
+
+// kickedMsg tells the program that an admin disconnected the session.
+type kickedMsg struct{}
+
+// adminModel is the view where admins see the connected sessions, and message
+// or kick them. Only sessions authenticated with one of the admin keys get
+// to it.
+type adminModel struct {
+	online      *sessionRegistry
+	self        string
+	fingerprint string
+	sessions    []sessionInfo
+	selected    int
+	input       textinput.Model
+	to          string // session id being messaged, adminAll or empty
+	result      string
+
+	nameStyle     lipgloss.Style
+	textStyle     lipgloss.Style
+	mutedStyle    lipgloss.Style
+	selectedStyle lipgloss.Style
+}
+
+func newAdminModel(online *sessionRegistry, self, fingerprint string, nameStyle, textStyle, mutedStyle, selectedStyle lipgloss.Style) adminModel {
+	input := textinput.New()
+	input.Placeholder = "Message..."
+	input.CharLimit = adminMaxMessage
+	input.Width = 50
+
+	a := adminModel{
+		online:        online,
+		self:          self,
+		fingerprint:   fingerprint,
+		input:         input,
+		nameStyle:     nameStyle,
+		textStyle:     textStyle,
+		mutedStyle:    mutedStyle,
+		selectedStyle: selectedStyle,
+	}
+	return a.refresh()
+}
+
+// refresh reloads the connected sessions, keeping the same one selected if
+// it's still there.
+func (a adminModel) refresh() adminModel {
+	var id string
+	if a.selected < len(a.sessions) {
+		id = a.sessions[a.selected].ID
+	}
+	a.sessions = a.online.list()
+	a.selected = max(min(a.selected, len(a.sessions)-1), 0)
+	if i := slices.IndexFunc(a.sessions, func(s sessionInfo) bool { return s.ID == id }); i >= 0 {
+		a.selected = i
+	}
+	return a
+}
+
+func (a adminModel) Update(msg tea.Msg) (adminModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if a.to != "" {
+		if ok {
+			switch key.String() {
+			case "enter":
+				a = a.send(sanitize(a.input.Value(), adminMaxMessage))
+				return a, nil
+			case "esc":
+				a.to = ""
+				a.input.Blur()
+				return a, nil
+			}
+		}
+		var cmd tea.Cmd
+		a.input, cmd = a.input.Update(msg)
+		return a, cmd
+	}
+	if !ok {
+		return a, nil
+	}
+
+	switch key.String() {
+	case "j", "down":
+		a.selected = min(a.selected+1, max(len(a.sessions)-1, 0))
+	case "k", "up":
+		a.selected = max(a.selected-1, 0)
+	case "m":
+		if s, ok := a.selectedSession(); ok {
+			return a.compose(s.ID)
+		}
+	case "M":
+		return a.compose(adminAll)
+	case "x":
+		s, ok := a.selectedSession()
+		switch {
+		case !ok:
+		case s.ID == a.self:
+			a.result = "You can't kick yourself."
+		case a.online.kick(s.ID):
+			log.Info("Admin kicked session", "admin", a.fingerprint, "session", s.ID, "user", s.User)
+			a.result = "Kicked " + s.ID + "."
+		default:
+			a.result = s.ID + " is already gone."
+		}
+		a = a.refresh()
+	}
+	return a, nil
+}
+
+func (a adminModel) selectedSession() (sessionInfo, bool) {
+	if a.selected >= len(a.sessions) {
+		return sessionInfo{}, false
+	}
+	return a.sessions[a.selected], true
+}
+
+// compose starts writing a message to the session with the given id, or to
+// every session for adminAll.
+func (a adminModel) compose(to string) (adminModel, tea.Cmd) {
+	a.to = to
+	a.result = ""
+	a.input.Reset()
+	return a, a.input.Focus()
+}
+
+// send sends text to the recipient being composed to.
+func (a adminModel) send(text string) adminModel {
+	to := a.to
+	a.to = ""
+	a.input.Blur()
+	if text == "" {
+		return a
+	}
+
+	sent := 0
+	for _, s := range a.online.list() {
+		if (to == adminAll && s.ID != a.self) || s.ID == to {
+			if a.online.send(s.ID, adminMsg{text}) {
+				sent++
+			}
+		}
+	}
+	log.Info("Admin sent message", "admin", a.fingerprint, "to", to, "sessions", sent, "message", text)
+	a.result = fmt.Sprintf("Sent to %d session(s).", sent)
+	return a
+}
+
+func (a adminModel) View() string {
+	var b strings.Builder
+	for i, s := range a.sessions {
+		line := fmt.Sprintf("%-8s  %s  %-12s  %s", s.ID, padRight(s.User, 16), time.Since(s.ConnectedAt).Truncate(time.Second), shortFingerprint(s.Fingerprint))
+		if s.ID == a.self {
+			line += "  (you)"
+		}
+		if i == a.selected {
+			b.WriteString(a.selectedStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString(a.textStyle.Render("  "+line) + "\n")
+		}
+	}
+
+	switch {
+	case a.to == adminAll:
+		fmt.Fprintf(&b, "\n%s\n%s\n", a.nameStyle.Render("Message everyone:"), a.input.View())
+	case a.to != "":
+		fmt.Fprintf(&b, "\n%s\n%s\n", a.nameStyle.Render("Message "+a.to+":"), a.input.View())
+	case a.result != "":
+		fmt.Fprintf(&b, "\n%s\n", a.mutedStyle.Render(a.result))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// shortFingerprint shortens a SHA256 key fingerprint for display.
+func shortFingerprint(fp string) string {
+	if fp == "" {
+		return "no key"
+	}
+	fp = strings.TrimPrefix(fp, "SHA256:")
+	return fp[:min(len(fp), 12)]
+}
+
+// isAdmin reports whether the key with the fingerprint fp is one of the
+// admin keys.
+func (a *app) isAdmin(fp string) bool {
+	return fp != "" && slices.Contains(a.cfg.AdminKeys, fp)
+}
+
+// showAdmin switches to the admin view.
+func (m model) showAdmin() (model, tea.Cmd) {
+	m.admin = newAdminModel(m.online, m.sessionID, m.fingerprint, m.aboutNameStyle, m.aboutStyle, m.subtleStyle, m.checkboxStyle)
+	m.state = stateAdmin
+	return m, onlineTick()
+}
+
+// updateAdmin handles the keys of the admin view, going back to the card on
+// esc unless a message is being written.
+func (m model) updateAdmin(msg tea.Msg) (model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && m.admin.to == "" {
+		switch msg.String() {
+		case "esc":
+			m.state = stateMenu
+			return m, nil
+		case "q":
+			return m.quit()
+		}
+	}
+	var cmd tea.Cmd
+	m.admin, cmd = m.admin.Update(msg)
+	return m, cmd
+}
+
+func (m model) adminView() string {
+	title := m.aboutNameStyle.Render(fmt.Sprintf("Admin: sessions (%d)", len(m.admin.sessions)))
+	tpl := m.hintLine()
+
+	s := fmt.Sprintf("%s\n\n%s\n\n%s", title, m.admin.View(), tpl)
+	return m.mainStyle.Render("\n" + s + "\n\n")
+}
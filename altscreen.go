@@ -0,0 +1,15 @@
+package main
+
+import "strings"
+
+// supportsAltScreen reports whether the client terminal can be expected to
+// have an alternate screen, judged from the TERM it advertised. The consoles
+// and old terminals without one would leave the card scrolled up in their
+// history, or garbled.
+func supportsAltScreen(term string) bool {
+	switch {
+	case term == "", term == "dumb", term == "linux", term == "ansi", strings.HasPrefix(term, "vt"):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// avatarDelay leaves time for the frame the avatar is drawn over to be
+	// rendered first.
+	avatarDelay   = 100 * time.Millisecond
+	avatarMaxCols = 24
+	avatarMinCols = 10
+	// kittyChunk is the most base64 data sent per kitty graphics escape.
+	kittyChunk = 4096
+)
+
+// pngSignature starts every PNG file.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// loadAvatar reads the PNG at path, returning nil if path is empty.
+func loadAvatar(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read avatar: %w", err)
+	}
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, fmt.Errorf("read avatar %s: not a PNG", path)
+	}
+	return data, nil
+}
+
+// imageProtocol is how a terminal displays inline images.
+type imageProtocol int
+
+const (
+	noImages imageProtocol = iota
+	kittyImages
+	itermImages
+)
+
+// detectImageProtocol guesses the image protocol of the client terminal from
+// its TERM, and from TERM_PROGRAM if the client forwards it with SendEnv.
+func detectImageProtocol(term string, environ []string) imageProtocol {
+	program := sessionEnv(environ, "TERM_PROGRAM")
+	switch {
+	case term == "xterm-kitty", term == "xterm-ghostty", program == "ghostty":
+		return kittyImages
+	case program == "iTerm.app", program == "WezTerm", term == "wezterm":
+		return itermImages
+	}
+	return noImages
+}
+
+// avatarFrame is what the position of the avatar depends on, it's drawn again
+// whenever it changes.
+type avatarFrame struct {
+	visible       bool
+	width, height int
+	theme         int
+	offset        int
+}
+
+// avatarMsg draws the avatar, unless the frame changed since it was sent.
+type avatarMsg struct{ frame avatarFrame }
+
+// avatarCols is how many columns the avatar takes at the top right of the
+// menu, next to the about text. It's 0 when it doesn't fit.
+func (m model) avatarCols() int {
+	cols := min(m.Width-m.aboutWidth()-6, avatarMaxCols)
+	if cols < avatarMinCols {
+		return 0
+	}
+	return cols
+}
+
+func (m model) avatarFrame() avatarFrame {
+	visible := m.avatar != noImages && !m.plain && m.state == stateMenu && !m.typing && !m.showHelp &&
+		!m.confirmingQuit && !m.tooSmall && m.goodbye == "" && m.avatarCols() > 0
+	return avatarFrame{visible, m.Width, m.Height, m.theme, m.menu.YOffset}
+}
+
+// redrawAvatar draws the avatar again once the next frame is rendered if
+// what it's drawn over changed since before, or removes it if it's hidden.
+func (m model) redrawAvatar(before avatarFrame) tea.Cmd {
+	frame := m.avatarFrame()
+	if m.avatar == noImages || frame == before {
+		return nil
+	}
+	if !frame.visible {
+		// Kitty images float over the text, unlike the iTerm2 ones which
+		// are overwritten with it.
+		if before.visible && m.avatar == kittyImages {
+			return m.writeAvatar("\x1b_Ga=d,d=I,i=1,q=2\x1b\\")
+		}
+		return nil
+	}
+	return tea.Tick(avatarDelay, func(time.Time) tea.Msg { return avatarMsg{frame} })
+}
+
+// drawAvatar draws the avatar at the top right of the window, leaving the
+// cursor where the renderer expects it.
+func (m model) drawAvatar() tea.Cmd {
+	cols := m.avatarCols()
+	rows := cols / 2 // Cells are about twice as tall as they're wide.
+	data := base64.StdEncoding.EncodeToString(m.avatarPNG)
+
+	var b strings.Builder
+	off := m.borderOffset()
+	fmt.Fprintf(&b, "\x1b7\x1b[%d;%dH", 2+off, m.Width-cols+off)
+	switch m.avatar {
+	case kittyImages:
+		for i := 0; i < len(data); i += kittyChunk {
+			chunk := data[i:min(i+kittyChunk, len(data))]
+			more := 0
+			if i+kittyChunk < len(data) {
+				more = 1
+			}
+			if i == 0 {
+				fmt.Fprintf(&b, "\x1b_Ga=T,f=100,i=1,C=1,q=2,c=%d,r=%d,m=%d;%s\x1b\\", cols, rows, more, chunk)
+			} else {
+				fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+			}
+		}
+	case itermImages:
+		fmt.Fprintf(&b, "\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a", len(m.avatarPNG), cols, rows, data)
+	}
+	b.WriteString("\x1b8")
+	return m.writeAvatar(b.String())
+}
+
+// writeAvatar writes the escape sequence s to the session directly, as the
+// renderer would cut it to the window width.
+func (m model) writeAvatar(s string) tea.Cmd {
+	w := io.Writer(m.sess)
+	return func() tea.Msg {
+		_, _ = io.WriteString(w, s)
+		return nil
+	}
+}
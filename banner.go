@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// bannerArt is "Kaustubh" in the figlet standard font.
+var bannerArt = strings.Trim(`
+ _  __               _         _     _
+| |/ /__ _ _   _ ___| |_ _   _| |__ | |__
+| ' // _`+"`"+` | | | / __| __| | | | '_ \| '_ \
+| . \ (_| | |_| \__ \ |_| |_| | |_) | | | |
+|_|\_\__,_|\__,_|___/\__|\__,_|_.__/|_| |_|
+`, "\n")
+
+// renderBanner colors the banner with a horizontal gradient going from the
+// accent to the link color of the theme.
+func (m model) renderBanner() string {
+	from, _ := colorful.Hex(themes[m.theme].accent.TrueColor)
+	to, _ := colorful.Hex(themes[m.theme].link.TrueColor)
+
+	lines := strings.Split(bannerArt, "\n")
+	width := lipgloss.Width(bannerArt)
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for x, r := range []rune(line) {
+			if r == ' ' {
+				b.WriteRune(r)
+				continue
+			}
+			c := from.BlendLuv(to, float64(x)/float64(width-1)).Clamped()
+			b.WriteString(m.renderer.NewStyle().Foreground(lipgloss.Color(c.Hex())).Render(string(r)))
+		}
+	}
+	return b.String()
+}
+
+// withBanner puts the banner and the tagline under it on top of the menu
+// body, only the tagline if there's no room for the banner. It returns the
+// new body and the offset of the lines below them.
+func (m model) withBanner(body string) (string, int) {
+	header := m.banner
+	tagline := m.taglineView()
+	if tagline != "" {
+		header += "\n" + tagline
+	}
+	if lipgloss.Width(m.banner) > m.Width-2 || lipgloss.Height(header)+1+lipgloss.Height(body)+4 > m.bodyHeight() {
+		header = tagline
+	}
+	if header == "" {
+		return body, 0
+	}
+	return header + "\n\n" + body, lipgloss.Height(header) + 1
+}
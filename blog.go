@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// postsFS holds the blog posts, Markdown files starting with a front-matter
+// block giving their title and date.
+//
+//go:embed posts
+var postsFS embed.FS
+
+const postDateFormat = "2006-01-02"
+
+type post struct {
+	title string
+	date  time.Time
+	body  string
+}
+
+// loadPosts reads the posts in the posts directory of fsys, newest first.
+func loadPosts(fsys fs.FS) ([]post, error) {
+	names, err := fs.Glob(fsys, "posts/*.md")
+	if err != nil {
+		return nil, err
+	}
+	posts := make([]post, 0, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("read post: %w", err)
+		}
+		p, err := parsePost(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("parse post %s: %w", name, err)
+		}
+		if p.title == "" {
+			p.title = strings.TrimSuffix(path.Base(name), ".md")
+		}
+		posts = append(posts, p)
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].date.After(posts[j].date)
+	})
+	return posts, nil
+}
+
+// parsePost splits a post into its front-matter, delimited by "---" lines,
+// and its body. Posts without front-matter are all body.
+func parsePost(data string) (post, error) {
+	front, body, ok := strings.Cut(strings.TrimPrefix(data, "---\n"), "\n---\n")
+	if !strings.HasPrefix(data, "---\n") || !ok {
+		return post{body: data}, nil
+	}
+
+	p := post{body: strings.TrimLeft(body, "\n")}
+	sc := bufio.NewScanner(strings.NewReader(front))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return p, fmt.Errorf("invalid front-matter line %q", line)
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		switch strings.TrimSpace(key) {
+		case "title":
+			p.title = value
+		case "date":
+			t, err := time.Parse(postDateFormat, value)
+			if err != nil {
+				return p, fmt.Errorf("invalid date %q: must be like %s", value, postDateFormat)
+			}
+			p.date = t
+		}
+	}
+	return p, nil
+}
+
+// showBlog switches to the list of posts.
+func (m model) showBlog() model {
+	m.filter = newListFilter()
+	m = m.paginateBlog()
+	m.state = stateBlog
+	return m
+}
+
+// blogMatches returns the indices of the posts matching the filter.
+func (m model) blogMatches() []int {
+	return m.filter.indices(len(m.posts), func(i int) []string { return []string{m.posts[i].title} })
+}
+
+// selectedPost returns the selected post of the list.
+func (m model) selectedPost() post {
+	return m.posts[m.blogMatches()[m.blogPages.selected]]
+}
+
+// paginateBlog fits the pages of the list of posts to the window.
+func (m model) paginateBlog() model {
+	m.blogPages = m.blogPages.resize(m.bodyHeight()-10, len(m.blogMatches()))
+	return m
+}
+
+// showPost renders the selected post into a viewport sized to the window.
+func (m model) showPost() model {
+	p := m.selectedPost()
+	width := max(m.Width-4, 20)
+	m.post = viewport.New(width, max(m.bodyHeight()-6, 1))
+	m.post.SetContent(m.renderMarkdown(p.body, width))
+	m.state = statePost
+	return m
+}
+
+// updateBlog handles the keys of the list of posts and of the post being
+// read.
+func (m model) updateBlog(msg tea.KeyMsg) (model, tea.Cmd) {
+	if m.state == statePost {
+		switch msg.String() {
+		case "esc", "backspace", "h", "left":
+			m.state = stateBlog
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.post, cmd = m.post.Update(msg)
+		return m, cmd
+	}
+
+	if filter, cmd, ok := m.filter.Update(msg); ok {
+		m.filter = filter
+		return m.paginateBlog(), cmd
+	}
+	matches := len(m.blogMatches())
+	switch msg.String() {
+	case "esc", "backspace":
+		m.state = stateMenu
+	case "j", "down":
+		m.blogPages = m.blogPages.move(1, matches)
+	case "k", "up":
+		m.blogPages = m.blogPages.move(-1, matches)
+	case "enter":
+		if matches > 0 {
+			m = m.showPost()
+		}
+	default:
+		var cmd tea.Cmd
+		m.blogPages, cmd = m.blogPages.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m model) blogView() string {
+	title := m.aboutNameStyle.Render("Blog")
+	if len(m.posts) == 0 {
+		s := fmt.Sprintf("%s\n\n%s\n\n%s", title, m.subtleStyle.Render("No posts yet, check back soon!"), m.hintLine())
+		return m.mainStyle.Render("\n" + s + "\n\n")
+	}
+
+	tpl := m.hintLine()
+
+	var b strings.Builder
+	if f := m.filter.View(); f != "" {
+		b.WriteString(f + "\n\n")
+	}
+	matches := m.blogMatches()
+	if len(matches) == 0 {
+		b.WriteString(m.subtleStyle.Render("No posts match.") + "\n")
+	}
+	start, end := m.blogPages.GetSliceBounds(len(matches))
+	for i, j := range matches[start:end] {
+		p := m.posts[j]
+		date := "          "
+		if !p.date.IsZero() {
+			date = p.date.Format(postDateFormat)
+		}
+		title := m.filter.highlight(p.title, m.aboutStyle, m.matchStyle())
+		b.WriteString(m.checkbox(m.subtleStyle.Render(date)+"  "+title, m.blogPages.selected == start+i) + "\n")
+	}
+	if page := m.blogPages.View(); page != "" {
+		b.WriteString("\n" + m.subtleStyle.Render(page) + "\n")
+	}
+
+	s := fmt.Sprintf("%s\n\n%s\n%s", title, b.String(), tpl)
+	return m.mainStyle.Render("\n" + s + "\n\n")
+}
+
+func (m model) postView() string {
+	title := m.aboutNameStyle.Render(m.selectedPost().title)
+	tpl := m.hintLine()
+
+	s := fmt.Sprintf("%s\n\n%s\n\n%s", title, m.post.View(), tpl)
+	return m.mainStyle.Render("\n" + s + "\n")
+}
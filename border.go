@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+const (
+	// cardTitle is written in the top border of the card.
+	cardTitle = "kaustubhpatange.com"
+	// borderMinWidth is the narrowest window the border is drawn in, below
+	// it the card needs all the room it can get.
+	borderMinWidth = 60
+)
+
+// parseBorder checks the name of a border of the card: none, rounded or
+// double. Empty is none.
+func parseBorder(name string) error {
+	switch name {
+	case "", "none", "rounded", "double":
+		return nil
+	}
+	return fmt.Errorf("invalid border %q: must be none, rounded or double", name)
+}
+
+// cardBorder returns the border drawn around the card, the one of the theme
+// or else the configured one, and whether there's one at all. It's left out
+// in plain mode and in windows too small for it.
+func (m model) cardBorder() (lipgloss.Border, bool) {
+	name := themes[m.theme].border
+	if name == "" {
+		name = m.borderName
+	}
+	if m.plain || m.windowWidth < borderMinWidth || m.windowHeight < minHeight+2 {
+		return lipgloss.Border{}, false
+	}
+	switch name {
+	case "rounded":
+		return m.glyphs.border, true
+	case "double":
+		return m.glyphs.doubleBorder, true
+	}
+	return lipgloss.Border{}, false
+}
+
+// resize lays the card out for a width x height window, the views getting
+// the room left inside the border.
+func (m model) resize(width, height int) model {
+	m.windowWidth, m.windowHeight = width, height
+	m.Width, m.Height = width, height
+	if _, ok := m.cardBorder(); ok {
+		m.Width, m.Height = width-2, height-2
+	}
+	m.tooSmall = m.Width < minWidth || m.Height < minHeight
+	m = m.scrollMenu()
+	switch m.state {
+	case stateQR:
+		m = m.showQR()
+	case stateResume:
+		offset := m.resume.YOffset
+		m = m.showResume()
+		m.resume.SetYOffset(offset)
+	case stateBlog:
+		m = m.paginateBlog()
+	case stateProjects:
+		m = m.paginateProjects()
+	case stateGuestbook:
+		m.guestbook = m.guestbook.withHeight(m.bodyHeight() - 8)
+	case stateChangelog:
+		offset := m.changelog.YOffset
+		m = m.showChangelog()
+		m.changelog.SetYOffset(offset)
+	case statePost:
+		offset := m.post.YOffset
+		m = m.showPost()
+		m.post.SetYOffset(offset)
+	}
+	return m
+}
+
+// borderOffset is how far the views are from the top left corner of the
+// window, 1 inside the border.
+func (m model) borderOffset() int {
+	if _, ok := m.cardBorder(); ok {
+		return 1
+	}
+	return 0
+}
+
+// withBorder draws the border around view, with the title of the card in
+// its top edge. The lines of view are cut or padded to fit inside.
+func (m model) withBorder(view string) string {
+	b, ok := m.cardBorder()
+	if !ok {
+		return view
+	}
+	style := m.renderer.NewStyle().Foreground(themes[m.theme].accent)
+	title := " " + cardTitle + " "
+	top := b.TopLeft + b.Top + title
+	top += strings.Repeat(b.Top, max(m.Width-lipgloss.Width(b.Top+title), 0)) + b.TopRight
+	if lipgloss.Width(top) > m.windowWidth {
+		top = b.TopLeft + strings.Repeat(b.Top, m.Width) + b.TopRight
+	}
+
+	lines := strings.Split(view, "\n")
+	var s strings.Builder
+	s.WriteString(style.Render(top) + "\n")
+	for i := range m.Height {
+		var line string
+		if i < len(lines) {
+			line = truncate.String(lines[i], uint(m.Width))
+		}
+		line += strings.Repeat(" ", max(m.Width-lipgloss.Width(line), 0))
+		s.WriteString(style.Render(b.Left) + line + style.Render(b.Right) + "\n")
+	}
+	s.WriteString(style.Render(b.BottomLeft + strings.Repeat(b.Bottom, m.Width) + b.BottomRight))
+	return s.String()
+}
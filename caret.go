@@ -0,0 +1,27 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const caretInterval = 400 * time.Millisecond
+
+type caretTickMsg struct{}
+
+func caretTick() tea.Cmd {
+	return tea.Tick(caretInterval, func(time.Time) tea.Msg {
+		return caretTickMsg{}
+	})
+}
+
+// caret renders the current frame of the caret, or nothing when the
+// animation is off.
+func (m model) caret() string {
+	if !m.animateCaret || m.plain {
+		return ""
+	}
+	frames := m.glyphs.caret
+	return " " + m.caretStyle.Render(frames[m.caretFrame%len(frames)])
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// loadChangelog reads the changelog from the markdown file at path, listing
+// the recent updates of the card under a "## date" heading per update,
+// newest first. There's none if path is empty, which leaves the changelog
+// out of the menu.
+func loadChangelog(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read changelog: %w", err)
+	}
+	return string(data), nil
+}
+
+// changelogUpdated returns the date of the first "## " heading of md, falling
+// back to the RFC 3339 build date.
+func changelogUpdated(md, build string) time.Time {
+	for _, line := range strings.Split(md, "\n") {
+		if heading, ok := strings.CutPrefix(line, "## "); ok {
+			if t, err := time.Parse(postDateFormat, strings.TrimSpace(heading)); err == nil {
+				return t
+			}
+			break
+		}
+	}
+	t, _ := time.Parse(time.RFC3339, build)
+	return t
+}
+
+// updatedText describes when the card with changelog md was last updated,
+// from the newest heading of the changelog or the build date without one.
+func updatedText(md string) string {
+	updated := changelogUpdated(md, date)
+	if updated.IsZero() {
+		return "recent updates"
+	}
+	return "updated " + updated.Format("Jan 2, 2006")
+}
+
+// showChangelog renders the changelog into a viewport sized to the window
+// and switches to the changelog view.
+func (m model) showChangelog() model {
+	width := max(m.Width-4, 20)
+	m.changelog = viewport.New(width, max(m.bodyHeight()-6, 1))
+	m.changelog.SetContent(m.renderMarkdown(m.changelogText, width))
+	m.state = stateChangelog
+	return m
+}
+
+// updateChangelog scrolls the changelog, or goes back to the menu.
+func (m model) updateChangelog(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "backspace", "h", "left":
+		m.state = stateMenu
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.changelog, cmd = m.changelog.Update(msg)
+	return m, cmd
+}
+
+func (m model) changelogView() string {
+	title := m.aboutNameStyle.Render("Changelog")
+	if !changelogUpdated(m.changelogText, date).IsZero() {
+		title += m.subtleStyle.Render(m.glyphs.dot + updatedText(m.changelogText))
+	}
+	tpl := m.hintLine()
+
+	s := fmt.Sprintf("%s\n\n%s\n\n%s", title, m.changelog.View(), tpl)
+	return m.mainStyle.Render("\n" + s + "\n")
+}
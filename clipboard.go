@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/ssh"
+	"github.com/muesli/termenv"
+)
+
+const statusTimeout = 2 * time.Second
+
+type clearStatusMsg struct{ id int }
+
+// supportsOSC52 reports whether the client terminal can be expected to handle
+// OSC 52 clipboard writes, judged from the TERM it advertised.
+func supportsOSC52(term string) bool {
+	switch {
+	case term == "", term == "dumb", term == "linux", strings.HasPrefix(term, "vt"):
+		return false
+	}
+	return true
+}
+
+// syncSession serializes the writes to a session. The SSH channel sends a
+// write in several packets, so the clipboard writes of copyToClipboard, run
+// by commands, could otherwise land in the middle of a frame the program is
+// rendering and garble both.
+type syncSession struct {
+	ssh.Session
+	mu sync.Mutex
+}
+
+func (s *syncSession) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Session.Write(p)
+}
+
+// copyToClipboard writes text to the client clipboard using OSC 52, it does
+// nothing when the terminal doesn't support it. out writes to a syncSession,
+// as the program renders at the same time.
+func copyToClipboard(out *termenv.Output, text string) tea.Cmd {
+	if out == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		out.Copy(text)
+		return nil
+	}
+}
+
+// setStatus shows a transient status line which is cleared after
+// statusTimeout, unless another status replaced it in the meantime.
+func (m model) setStatus(text string) (model, tea.Cmd) {
+	return m.setStatusFor(text, statusTimeout)
+}
+
+// setStatusFor shows a status line which is cleared after d.
+func (m model) setStatusFor(text string, d time.Duration) (model, tea.Cmd) {
+	m.statusID++
+	m.status = text
+	id := m.statusID
+	return m, tea.Tick(d, func(time.Time) tea.Msg {
+		return clearStatusMsg{id}
+	})
+}
+
+// copyChoice copies the URL of the current choice to the client clipboard.
+func (m model) copyChoice() (model, tea.Cmd) {
+	_, url := m.choiceLink(m.Choice)
+	if m.clipboard == nil || url == "" {
+		return m, nil
+	}
+	m, tick := m.setStatus("Copied!")
+	return m, tea.Batch(copyToClipboard(m.clipboard, copyText(url)), tick)
+}
+
+// copyAll copies every link of the menu to the client clipboard, a label and
+// URL per line.
+func (m model) copyAll() (model, tea.Cmd) {
+	if m.clipboard == nil {
+		return m, nil
+	}
+	var labels, urls []string
+	for i := range m.items {
+		if label, url := m.choiceLink(i); url != "" {
+			labels, urls = append(labels, label), append(urls, copyText(url))
+		}
+	}
+	width := 0
+	for _, label := range labels {
+		width = max(width, lipgloss.Width(label))
+	}
+	var b strings.Builder
+	for i, label := range labels {
+		fmt.Fprintf(&b, "%s  %s\n", padRight(label, width), urls[i])
+	}
+	m, tick := m.setStatus("Copied all links!")
+	return m, tea.Batch(copyToClipboard(m.clipboard, b.String()), tick)
+}
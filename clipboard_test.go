@@ -0,0 +1,48 @@
+package main
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/ssh"
+)
+
+// packetSession writes a byte at a time, like the SSH channel sending a write
+// in several packets.
+type packetSession struct {
+	ssh.Session
+	mu  sync.Mutex
+	out strings.Builder
+}
+
+func (s *packetSession) Write(p []byte) (int, error) {
+	for _, b := range p {
+		s.mu.Lock()
+		s.out.WriteByte(b)
+		s.mu.Unlock()
+		runtime.Gosched()
+	}
+	return len(p), nil
+}
+
+func TestSyncSessionKeepsWritesWhole(t *testing.T) {
+	inner := &packetSession{}
+	s := &syncSession{Session: inner}
+	frame, copied := strings.Repeat("f", 200), "\x1b]52;c;"+strings.Repeat("c", 200)+"\x07"
+
+	var wg sync.WaitGroup
+	for _, w := range []string{frame, copied} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Write([]byte(w))
+		}()
+	}
+	wg.Wait()
+
+	if out := inner.out.String(); out != frame+copied && out != copied+frame {
+		t.Errorf("writes were interleaved: %q", out)
+	}
+}
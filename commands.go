@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// commands are the plain text responses for `ssh host <command>`, so the
+// card can be scripted without going through the TUI.
+type commands map[string]func(w io.Writer)
+
+func newCommands(items *itemStore, tr translation) commands {
+	return commands{
+		"about": func(w io.Writer) {
+			writeAbout(w, tr)
+		},
+		"resume": func(w io.Writer) {
+			fmt.Fprintf(w, "%s\nPDF: %s\n", strings.TrimSpace(resumeMarkdown), RESUME_URL)
+		},
+		"github": func(w io.Writer) {
+			fmt.Fprintln(w, GITHUB_URL)
+		},
+		"links": func(w io.Writer) {
+			writeLinks(w, items.get())
+		},
+	}
+}
+
+func writeAbout(w io.Writer, tr translation) {
+	fmt.Fprintln(w, wordwrap.String(fmt.Sprintf(tr.About, "Hi", myName), aboutMaxWidth))
+}
+
+// writeLinks writes the links of items tab separated, so scripts can cut the
+// fields. They're the same items as the menu so the two don't drift.
+func writeLinks(w io.Writer, items []menuItem) {
+	for _, item := range items {
+		if item.url == "" {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\n", item.label, copyText(item.url))
+	}
+}
+
+// writeCard writes a plain text version of the card, for sessions which
+// can't show the TUI.
+func writeCard(w io.Writer, tr translation, items []menuItem) {
+	writeAbout(w, tr)
+	fmt.Fprintln(w)
+	writeLinks(w, items)
+}
+
+// writeCard writes the card for session s to w, in the language of its
+// locale.
+func (a *app) writeCard(w io.Writer, s ssh.Session) {
+	writeCard(w, a.locales.forEnv(s.Environ()), a.items.get())
+}
+
+// commandMiddleware answers sessions which ran a command or didn't request a
+// PTY with plain text, instead of passing them on to the TUI. Sessions
+// without either get the card written by card. The admin commands are left
+// out of the list and denied to the clients whose key fingerprint isAdmin
+// doesn't accept.
+func commandMiddleware(cmds, admin commands, isAdmin func(fingerprint string) bool, card func(io.Writer, ssh.Session)) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			_, _, isPty := s.Pty()
+			if len(s.Command()) == 0 {
+				if isPty {
+					next(s)
+				} else {
+					card(s, s)
+				}
+				return
+			}
+
+			// Translate newlines for clients that did request a PTY.
+			var w io.Writer = s
+			if isPty {
+				w = crlfWriter{s}
+			}
+			name := strings.Join(s.Command(), " ")
+			if cmd, ok := admin[name]; ok {
+				if !isAdmin(fingerprint(s)) {
+					log.Warn("Denied admin command", "command", name, "ip", remoteIP(s), "fingerprint", fingerprint(s))
+					fmt.Fprintln(s.Stderr(), "permission denied.")
+					_ = s.Exit(1)
+					return
+				}
+				cmd(w)
+				return
+			}
+			cmd, ok := cmds[name]
+			if !ok {
+				fmt.Fprintf(s.Stderr(), "Unknown command %q.\nUsage: ssh <host> <command>, with one of: %s\n", name, strings.Join(cmds.names(), ", "))
+				_ = s.Exit(1)
+				return
+			}
+			cmd(w)
+		}
+	}
+}
+
+func (c commands) names() []string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// crlfWriter writes "\r\n" for every "\n".
+type crlfWriter struct{ w io.Writer }
+
+func (c crlfWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(c.w, strings.ReplaceAll(string(p), "\n", "\r\n")); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/ssh"
+)
+
+// fakeSession is a session without a PTY running command, reading from in
+// and writing to out.
+type fakeSession struct {
+	ssh.Session
+	command []string
+	in      io.Reader
+	out     bytes.Buffer
+}
+
+func (s *fakeSession) Pty() (ssh.Pty, <-chan ssh.Window, bool) { return ssh.Pty{}, nil, false }
+func (s *fakeSession) Command() []string                       { return s.command }
+func (s *fakeSession) Read(p []byte) (int, error)              { return s.in.Read(p) }
+func (s *fakeSession) Write(p []byte) (int, error)             { return s.out.Write(p) }
+
+func TestCommandMiddlewareWritesCardWithoutPty(t *testing.T) {
+	locales, err := loadLocales(localesFS, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := locales.english()
+	items := newMenuItems("me@example.com", nil, nil, "", defaultLinks)
+	card := func(w io.Writer, _ ssh.Session) { writeCard(w, tr, items) }
+
+	s := &fakeSession{}
+	next := func(ssh.Session) { t.Error("session without a PTY passed on to the TUI") }
+	commandMiddleware(commands{}, commands{}, func(string) bool { return false }, card)(next)(s)
+
+	out := s.out.String()
+	if !strings.Contains(out, myName) {
+		t.Errorf("card is missing the about text:\n%s", out)
+	}
+	for _, l := range defaultLinks {
+		if !strings.Contains(out, l.URL) {
+			t.Errorf("card is missing the %s link:\n%s", l.Label, out)
+		}
+	}
+	if !strings.Contains(out, "me@example.com") {
+		t.Errorf("card is missing the contact address:\n%s", out)
+	}
+}
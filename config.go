@@ -0,0 +1,453 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHost = "0.0.0.0"
+	defaultPort = "22"
+
+	defaultHostKeyDir = ".ssh"
+
+	defaultIdleTimeout     = 5 * time.Minute
+	defaultIdleWarning     = 30 * time.Second
+	defaultShutdownTimeout = 30 * time.Second
+	defaultMaxSessions     = 100
+	defaultMaxSessionsIP   = 3
+	defaultRateLimit       = 10
+
+	defaultVisitorsFile  = "visitors.count"
+	defaultGuestbookFile = "guestbook.json"
+	defaultPrefsFile     = "prefs.json"
+	defaultResumePDF     = "resume.pdf"
+	defaultGitHubUser    = "KaustubhPatange"
+	defaultGeoIPDB       = "GeoLite2-City.mmdb"
+
+	defaultLogFormat = "text"
+
+	defaultMetricsAddr = ":9090"
+)
+
+// middlewareToggles are the middleware which can be turned off with
+// SSH_DISABLE_MIDDLEWARE, they're all on by default.
+type middlewareToggles struct {
+	Logging     bool
+	ActiveTerm  bool
+	IdleTimeout bool
+	RateLimit   bool
+	Recover     bool
+}
+
+// Config holds the server settings read from the environment.
+type Config struct {
+	Host string
+	Port string
+	// Listen are the addresses to serve on, SSH_LISTEN takes precedence over
+	// SSH_HOST and SSH_PORT.
+	Listen      []string
+	HostKeyDir  string
+	IdleTimeout time.Duration
+	// IdleWarning is how long before IdleTimeout visitors are warned they're
+	// about to be disconnected, 0 to not warn them.
+	IdleWarning time.Duration
+	// MaxDuration is how long sessions can last, 0 for as long as they're
+	// active.
+	MaxDuration time.Duration
+	MaxSessions int
+	// MaxSessionsPerIP is how many sessions a single IP can have connected
+	// at once.
+	MaxSessionsPerIP int
+	// ShutdownTimeout is how long sessions have to end when the server
+	// stops, 0 to close them right away.
+	ShutdownTimeout time.Duration
+	// RateLimit is the number of connections allowed per IP and minute.
+	RateLimit int
+	// Denylist is the path of the IP denylist, empty to disable it.
+	Denylist string
+	// VisitorsFile is where the visitor count is persisted.
+	VisitorsFile string
+	// GuestbookFile is where the guestbook entries are persisted.
+	GuestbookFile string
+	// PrefsFile is where the preferences of returning visitors are persisted.
+	PrefsFile string
+	// ResumePDF is the resume served over SFTP, it's left out if missing.
+	ResumePDF string
+	// GitHubUser is whose repositories are listed in the projects view.
+	GitHubUser string
+	// ContactEmail is the address shown in the contact view, which is left
+	// out of the menu when it's not set.
+	ContactEmail string
+	// GeoIPDB is the MaxMind GeoLite2 City database visitors are greeted
+	// from their city with, skipped if it's missing.
+	GeoIPDB string
+	// LinksFile is a JSON list of the links of the menu, replacing the
+	// default ones when set.
+	LinksFile string
+	// TaglinesFile lists the taglines shown under the banner, one per line.
+	// There's no tagline unless it's set.
+	TaglinesFile string
+	// SkillsFile is a JSON list of the languages I know with how well, shown
+	// in the skills view which is left out of the menu when it's not set.
+	SkillsFile string
+	// ChangelogFile is a markdown list of the recent updates of the card, the
+	// changelog being left out of the menu when it's not set.
+	ChangelogFile string
+	// LocalesDir holds translations of the card, a JSON file per locale like
+	// de.json, shown to visitors whose client forwards that locale.
+	LocalesDir string
+	// LogFormat is either "text" or "json".
+	LogFormat string
+	// LogSampleInterval logs the sessions of every IP at most once per
+	// interval, 0 to log them all.
+	LogSampleInterval time.Duration
+	// ReverseDNS logs the hostname of the IP of every session, which can
+	// take a while and tells the resolver who visits.
+	ReverseDNS bool
+	// MetricsAddr is where the Prometheus metrics are served, empty to
+	// disable them.
+	MetricsAddr string
+	// HealthAddr is where /healthz is served, empty to disable it. It shares
+	// the metrics server when both are on the same address.
+	HealthAddr string
+	// Middleware are the middleware turned on.
+	Middleware middlewareToggles
+	// ProxyProtocol requires a PROXY protocol header on every connection, to
+	// recover the client address behind a load balancer.
+	ProxyProtocol bool
+	// AdminKeys are the SHA256 fingerprints of the keys allowed in the admin
+	// view.
+	AdminKeys []string
+	// ThemeFile is a JSON file of themes replacing or adding to the built-in
+	// ones.
+	ThemeFile string
+	// Theme is the theme sessions start with, instead of the one matching the
+	// terminal background.
+	Theme string
+	// Typewriter animates the about text when a session starts.
+	Typewriter bool
+	// AskName asks visitors for their name before showing the card, to
+	// greet them with it.
+	AskName bool
+	// AvatarFile is a PNG of me shown next to the about text in terminals
+	// supporting inline images, which can be slow over laggy connections.
+	// There's no avatar unless it's set.
+	AvatarFile string
+	// Plain renders the card without styles, alt screen or animations for
+	// every session, visitors can also switch to it with P.
+	Plain bool
+	// Maintenance shows new sessions MaintenanceMessage instead of the card,
+	// as does MaintenanceFile existing when it's set.
+	Maintenance        bool
+	MaintenanceFile    string
+	MaintenanceMessage string
+	// MaintenanceDrain disconnects the sessions already connected when
+	// maintenance mode is turned on by a reload.
+	MaintenanceDrain bool
+	// PublicHost is where visitors reach the card, with the port when it's
+	// not 22, shown for them to share it.
+	PublicHost string
+	// Border is the border drawn around the card, none, rounded or double.
+	// Themes setting one override it.
+	Border string
+	// NoAltScreen renders the card below the prompt of every session instead
+	// of in the alt screen, as for the terminals without one.
+	NoAltScreen bool
+	// EastAsianWidth counts the characters of ambiguous width as two cells
+	// when laying out the card, for CJK terminals.
+	EastAsianWidth bool
+	// ReducedMotion stops the caret next to the selected menu item from
+	// pulsing.
+	ReducedMotion bool
+	// RestoreView brings visitors reconnecting with the same key within
+	// RestoreTTL back to the view they left.
+	RestoreView bool
+	RestoreTTL  time.Duration
+	// ConfirmQuit asks visitors to confirm before q quits, ctrl+c always
+	// quits right away.
+	ConfirmQuit bool
+	// OpenURLs also opens the links chosen in a browser on the server, as a
+	// convenience when running the card on your own machine. It must stay
+	// off on a public server, where it would let anyone connecting launch
+	// processes on the host, see openChoice.
+	OpenURLs bool
+	// HumanGate waits for visitors to press a key before starting the card.
+	// Commands and clients without a PTY aren't asked.
+	HumanGate bool
+	// Banner is shown by clients before the session starts, BannerFile takes
+	// precedence when set.
+	Banner     string
+	BannerFile string
+	// SpotifyClientID, SpotifyClientSecret and SpotifyRefreshToken are the
+	// credentials of the Spotify app showing what I'm listening to, the
+	// widget is hidden unless they're all set.
+	SpotifyClientID     string
+	SpotifyClientSecret string
+	SpotifyRefreshToken string
+	// WeatherLat and WeatherLon are where the weather in the footer is
+	// fetched for, found from WeatherCity if they're left out. The widget is
+	// hidden when none is set.
+	WeatherLat  string
+	WeatherLon  string
+	WeatherCity string
+	// RecordDir is where sessions are recorded as asciinema cast files,
+	// empty to not record them.
+	RecordDir string
+	// AccessLog is the file every session is logged to as JSON once it
+	// ended, rotated by size, empty to only log to stdout.
+	AccessLog string
+}
+
+// loadConfig reads the Config from the environment and the config file at
+// SSH_CONFIG, the variables overriding the file, falling back to the
+// defaults for unset settings.
+func loadConfig() (Config, error) {
+	e, err := loadEnvironment(os.Getenv("SSH_CONFIG"))
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := Config{
+		Host:                e.or("SSH_HOST", defaultHost),
+		Port:                e.or("SSH_PORT", defaultPort),
+		HostKeyDir:          e.or("SSH_HOSTKEY_DIR", defaultHostKeyDir),
+		Denylist:            e.get("SSH_DENYLIST"),
+		VisitorsFile:        e.or("SSH_VISITORS_FILE", defaultVisitorsFile),
+		GuestbookFile:       e.or("SSH_GUESTBOOK_FILE", defaultGuestbookFile),
+		PrefsFile:           e.or("SSH_PREFS_FILE", defaultPrefsFile),
+		ResumePDF:           e.or("SSH_RESUME_PDF", defaultResumePDF),
+		GitHubUser:          e.or("SSH_GITHUB_USER", defaultGitHubUser),
+		ContactEmail:        e.get("SSH_CONTACT_EMAIL"),
+		LinksFile:           e.get("SSH_LINKS_FILE"),
+		TaglinesFile:        e.get("SSH_TAGLINES"),
+		LocalesDir:          e.get("SSH_LOCALES_DIR"),
+		SkillsFile:          e.get("SSH_SKILLS_FILE"),
+		ChangelogFile:       e.get("SSH_CHANGELOG_FILE"),
+		GeoIPDB:             e.or("SSH_GEOIP_DB", defaultGeoIPDB),
+		RecordDir:           e.get("SSH_RECORD_DIR"),
+		AccessLog:           e.get("SSH_ACCESS_LOG"),
+		ThemeFile:           e.get("SSH_THEME_FILE"),
+		AvatarFile:          e.get("SSH_AVATAR_FILE"),
+		Theme:               e.get("SSH_THEME"),
+		Border:              e.get("SSH_BORDER"),
+		PublicHost:          e.or("SSH_PUBLIC_HOST", defaultPublicHost),
+		MaintenanceFile:     e.get("SSH_MAINTENANCE_FILE"),
+		MaintenanceMessage:  e.or("SSH_MAINTENANCE_MESSAGE", defaultMaintenanceMessage),
+		Banner:              e.get("SSH_BANNER"),
+		BannerFile:          e.get("SSH_BANNER_FILE"),
+		WeatherLat:          e.get("SSH_WEATHER_LAT"),
+		WeatherLon:          e.get("SSH_WEATHER_LON"),
+		WeatherCity:         e.get("SSH_WEATHER_CITY"),
+		SpotifyClientID:     e.get("SSH_SPOTIFY_CLIENT_ID"),
+		SpotifyClientSecret: e.get("SSH_SPOTIFY_CLIENT_SECRET"),
+		SpotifyRefreshToken: e.get("SSH_SPOTIFY_REFRESH_TOKEN"),
+		LogFormat:           e.or("SSH_LOG_FORMAT", defaultLogFormat),
+		MetricsAddr:         defaultMetricsAddr,
+		HealthAddr:          e.get("SSH_HEALTH_ADDR"),
+	}
+	// Unlike the other settings, setting it empty is meaningful.
+	if addr, ok := e.lookup("SSH_METRICS_ADDR"); ok {
+		cfg.MetricsAddr = addr
+	}
+	if p, err := strconv.Atoi(cfg.Port); err != nil || p < 1 || p > 65535 {
+		return cfg, fmt.Errorf("invalid %s %q: must be a number between 1 and 65535", e.name("SSH_PORT"), cfg.Port)
+	}
+	cfg.Listen = []string{net.JoinHostPort(cfg.Host, cfg.Port)}
+	if v := e.get("SSH_LISTEN"); v != "" {
+		cfg.Listen = nil
+		for _, addr := range strings.Split(v, ",") {
+			addr = strings.TrimSpace(addr)
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				return cfg, fmt.Errorf("invalid %s address %q: %w", e.name("SSH_LISTEN"), addr, err)
+			}
+			cfg.Listen = append(cfg.Listen, addr)
+		}
+	}
+
+	cfg.Middleware = middlewareToggles{true, true, true, true, true}
+	for _, name := range strings.Split(e.get("SSH_DISABLE_MIDDLEWARE"), ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "logging":
+			cfg.Middleware.Logging = false
+		case "activeterm":
+			cfg.Middleware.ActiveTerm = false
+		case "idle-timeout":
+			cfg.Middleware.IdleTimeout = false
+		case "rate-limit":
+			cfg.Middleware.RateLimit = false
+		case "recover":
+			cfg.Middleware.Recover = false
+		default:
+			return cfg, fmt.Errorf("invalid %s %q: must be a list of logging, activeterm, idle-timeout, rate-limit and recover", e.name("SSH_DISABLE_MIDDLEWARE"), name)
+		}
+	}
+
+	for _, fp := range strings.Split(e.get("SSH_ADMIN_KEYS"), ",") {
+		if fp = strings.TrimSpace(fp); fp == "" {
+			continue
+		}
+		if !strings.HasPrefix(fp, "SHA256:") {
+			return cfg, fmt.Errorf("invalid %s fingerprint %q: must be like SHA256:...", e.name("SSH_ADMIN_KEYS"), fp)
+		}
+		cfg.AdminKeys = append(cfg.AdminKeys, fp)
+	}
+
+	if err := parseCoordinates(cfg.WeatherLat, cfg.WeatherLon); err != nil {
+		return cfg, fmt.Errorf("invalid %s and %s: %w", e.name("SSH_WEATHER_LAT"), e.name("SSH_WEATHER_LON"), err)
+	}
+	if err := parsePublicHost(cfg.PublicHost); err != nil {
+		return cfg, fmt.Errorf("invalid %s %q: %w", e.name("SSH_PUBLIC_HOST"), cfg.PublicHost, err)
+	}
+	if err := parseBorder(cfg.Border); err != nil {
+		return cfg, fmt.Errorf("invalid %s: %w", e.name("SSH_BORDER"), err)
+	}
+
+	if cfg.LogFormat != "text" && cfg.LogFormat != "json" {
+		return cfg, fmt.Errorf("invalid %s %q: must be text or json", e.name("SSH_LOG_FORMAT"), cfg.LogFormat)
+	}
+
+	if cfg.IdleTimeout, err = e.duration("SSH_IDLE_TIMEOUT", defaultIdleTimeout); err != nil {
+		return cfg, err
+	}
+	if cfg.IdleWarning, err = e.durationOrZero("SSH_IDLE_WARNING", defaultIdleWarning); err != nil {
+		return cfg, err
+	}
+	// The default warning is left out of idle timeouts too short for it.
+	if cfg.IdleWarning >= cfg.IdleTimeout && e.get("SSH_IDLE_WARNING") == "" {
+		cfg.IdleWarning = 0
+	} else if cfg.IdleWarning >= cfg.IdleTimeout {
+		return cfg, fmt.Errorf("invalid %s %s: must be shorter than %s", e.name("SSH_IDLE_WARNING"), cfg.IdleWarning, e.name("SSH_IDLE_TIMEOUT"))
+	}
+	if cfg.MaxDuration, err = e.duration("SSH_MAX_DURATION", 0); err != nil {
+		return cfg, err
+	}
+	if cfg.ShutdownTimeout, err = e.durationOrZero("SSH_SHUTDOWN_TIMEOUT", defaultShutdownTimeout); err != nil {
+		return cfg, err
+	}
+	if cfg.LogSampleInterval, err = e.durationOrZero("SSH_LOG_SAMPLE", 0); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxSessions, err = e.int("SSH_MAX_SESSIONS", defaultMaxSessions); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxSessionsPerIP, err = e.int("SSH_MAX_SESSIONS_PER_IP", defaultMaxSessionsIP); err != nil {
+		return cfg, err
+	}
+	if cfg.RateLimit, err = e.int("SSH_RATE_LIMIT", defaultRateLimit); err != nil {
+		return cfg, err
+	}
+	if cfg.ReverseDNS, err = e.bool("SSH_REVERSE_DNS", false); err != nil {
+		return cfg, err
+	}
+	if cfg.ProxyProtocol, err = e.bool("SSH_PROXY_PROTOCOL", false); err != nil {
+		return cfg, err
+	}
+	if cfg.Typewriter, err = e.bool("SSH_TYPEWRITER", false); err != nil {
+		return cfg, err
+	}
+	if cfg.AskName, err = e.bool("SSH_ASK_NAME", false); err != nil {
+		return cfg, err
+	}
+	if cfg.ConfirmQuit, err = e.bool("SSH_CONFIRM_QUIT", false); err != nil {
+		return cfg, err
+	}
+	if cfg.OpenURLs, err = e.bool("SSH_OPEN_URLS", false); err != nil {
+		return cfg, err
+	}
+	if cfg.HumanGate, err = e.bool("SSH_HUMAN_GATE", false); err != nil {
+		return cfg, err
+	}
+	if cfg.Plain, err = e.bool("SSH_PLAIN", false); err != nil {
+		return cfg, err
+	}
+	if cfg.NoAltScreen, err = e.bool("SSH_NO_ALTSCREEN", false); err != nil {
+		return cfg, err
+	}
+	if cfg.EastAsianWidth, err = e.bool("SSH_EAST_ASIAN_WIDTH", false); err != nil {
+		return cfg, err
+	}
+	if cfg.ReducedMotion, err = e.bool("SSH_REDUCED_MOTION", false); err != nil {
+		return cfg, err
+	}
+	if cfg.Maintenance, err = e.bool("SSH_MAINTENANCE", false); err != nil {
+		return cfg, err
+	}
+	if cfg.MaintenanceDrain, err = e.bool("SSH_MAINTENANCE_DRAIN", false); err != nil {
+		return cfg, err
+	}
+	if cfg.RestoreView, err = e.bool("SSH_RESTORE_VIEW", false); err != nil {
+		return cfg, err
+	}
+	if cfg.RestoreTTL, err = e.duration("SSH_RESTORE_TTL", defaultRestoreTTL); err != nil {
+		return cfg, err
+	}
+	return cfg, e.checkUnknown()
+}
+
+// or returns the setting key, or def if it's unset or empty.
+func (e *environment) or(key, def string) string {
+	if v := e.get(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// duration parses the setting key as a positive duration, or returns def if
+// it's unset or empty.
+func (e *environment) duration(key string, def time.Duration) (time.Duration, error) {
+	v := e.get(key)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive duration like 5m", e.name(key), v)
+	}
+	return d, nil
+}
+
+// durationOrZero is like duration, but also accepts 0.
+func (e *environment) durationOrZero(key string, def time.Duration) (time.Duration, error) {
+	v := e.get(key)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a duration like 30s, or 0", e.name(key), v)
+	}
+	return d, nil
+}
+
+// int parses the setting key as a positive integer, or returns def if it's
+// unset or empty.
+func (e *environment) int(key string, def int) (int, error) {
+	v := e.get(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive number", e.name(key), v)
+	}
+	return n, nil
+}
+
+// bool parses the setting key as a boolean, or returns def if it's unset or
+// empty.
+func (e *environment) bool(key string, def bool) (bool, error) {
+	v := e.get(key)
+	if v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: must be true or false", e.name(key), v)
+	}
+	return b, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// secretFields are the Config fields shown as *** by writeConfig, when
+// they're set.
+var secretFields = map[string]bool{
+	"SpotifyClientSecret": true,
+	"SpotifyRefreshToken": true,
+}
+
+// adminCommands are the commands only admins can run.
+func (a *app) adminCommands() commands {
+	return commands{
+		"config": func(w io.Writer) {
+			writeConfig(w, a.config.get())
+		},
+	}
+}
+
+// writeConfig writes cfg a field per line, with the secrets redacted, for
+// admins to check what the server runs with since the last reload.
+func writeConfig(w io.Writer, cfg Config) {
+	writeFields(w, "", reflect.ValueOf(cfg))
+}
+
+func writeFields(w io.Writer, prefix string, v reflect.Value) {
+	t := v.Type()
+	for i := range t.NumField() {
+		name, field := prefix+t.Field(i).Name, v.Field(i)
+		if field.Kind() == reflect.Struct {
+			writeFields(w, name+".", field)
+			continue
+		}
+		fmt.Fprintf(w, "%s: %s\n", name, formatField(name, field))
+	}
+}
+
+func formatField(name string, v reflect.Value) string {
+	switch value := v.Interface().(type) {
+	case string:
+		if secretFields[name] && value != "" {
+			return "***"
+		}
+		return fmt.Sprintf("%q", value)
+	case []string:
+		return "[" + strings.Join(value, ", ") + "]"
+	case time.Duration:
+		return value.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConfigCommandShowsReloadedConfig(t *testing.T) {
+	t.Setenv("SSH_CONFIG", "")
+	t.Setenv("SSH_RATE_LIMIT", "10")
+	t.Setenv("SSH_SPOTIFY_CLIENT_SECRET", "hunter2")
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &app{cfg: cfg, config: &configStore{cfg: cfg}}
+	locales, err := loadLocales(localesFS, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := &itemStore{}
+	cmds := newCommands(items, locales.english())
+	files, err := newSFTPFS("", cmds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &reloader{
+		cfg:         cfg,
+		config:      a.config,
+		limiter:     newRateLimiter(cfg.RateLimit, 0),
+		items:       items,
+		taglines:    &taglineStore{},
+		cmds:        cmds,
+		files:       files,
+		maintenance: newMaintenanceMode(cfg),
+		online:      newSessionRegistry(),
+	}
+
+	t.Setenv("SSH_RATE_LIMIT", "5")
+	t.Setenv("SSH_MAINTENANCE_MESSAGE", "Moving house")
+	r.reload()
+
+	var b bytes.Buffer
+	a.adminCommands()["config"](&b)
+	out := b.String()
+	for _, want := range []string{"RateLimit: 5\n", `MaintenanceMessage: "Moving house"`, "SpotifyClientSecret: ***\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("config is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("config shows the Spotify secret:\n%s", out)
+	}
+}
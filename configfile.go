@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// environment is where the settings are read from: the environment
+// variables, or else the config file. Settings of the file are named like the
+// variables without the SSH_ prefix and in lowercase, idle_timeout for
+// SSH_IDLE_TIMEOUT, lists being joined with commas.
+type environment struct {
+	path string            // of the config file, empty without one
+	file map[string]string // settings of the config file, by variable
+	used map[string]bool
+}
+
+// loadEnvironment reads the YAML config file at path, if it isn't empty.
+func loadEnvironment(path string) (*environment, error) {
+	e := &environment{path: path, file: make(map[string]string), used: make(map[string]bool)}
+	if path == "" {
+		return e, nil
+	}
+	if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+		return nil, fmt.Errorf("invalid SSH_CONFIG %q: must be a .yaml or .yml file", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	var settings map[string]any
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&settings); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	for name, v := range settings {
+		var value string
+		switch v := v.(type) {
+		case nil:
+		case []any:
+			values := make([]string, len(v))
+			for i, v := range v {
+				if !isScalar(v) {
+					return nil, fmt.Errorf("parse config %s: %s: must be a list of values", path, name)
+				}
+				values[i] = fmt.Sprint(v)
+			}
+			value = strings.Join(values, ",")
+		default:
+			if !isScalar(v) {
+				return nil, fmt.Errorf("parse config %s: %s: must be a value or a list of values", path, name)
+			}
+			value = fmt.Sprint(v)
+		}
+		e.file["SSH_"+strings.ToUpper(name)] = value
+	}
+	return e, nil
+}
+
+func isScalar(v any) bool {
+	switch v.(type) {
+	case string, bool, int, float64:
+		return true
+	}
+	return false
+}
+
+// lookup returns the setting key, from the environment variable if it's set
+// even if empty, or else from the config file.
+func (e *environment) lookup(key string) (string, bool) {
+	e.used[key] = true
+	if v, ok := os.LookupEnv(key); ok {
+		return v, true
+	}
+	v, ok := e.file[key]
+	return v, ok
+}
+
+// get returns the setting key, empty if it's unset.
+func (e *environment) get(key string) string {
+	v, _ := e.lookup(key)
+	return v
+}
+
+// name returns how to refer to the setting key in errors, the name in the
+// config file when it's set there.
+func (e *environment) name(key string) string {
+	if _, ok := os.LookupEnv(key); !ok {
+		if _, ok := e.file[key]; ok {
+			return fmt.Sprintf("%s of %s", fileName(key), e.path)
+		}
+	}
+	return key
+}
+
+// checkUnknown returns an error naming the settings of the config file which
+// aren't settings, most likely typos.
+func (e *environment) checkUnknown() error {
+	var unknown []string
+	for key := range e.file {
+		if !e.used[key] {
+			unknown = append(unknown, fileName(key))
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	slices.Sort(unknown)
+	return fmt.Errorf("parse config %s: unknown settings %s", e.path, strings.Join(unknown, ", "))
+}
+
+// fileName returns the name of the setting key in the config file.
+func fileName(key string) string {
+	return strings.ToLower(strings.TrimPrefix(key, "SSH_"))
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (m model) showContact() model {
+	m.state = stateContact
+	return m
+}
+
+// contactView shows my email address as a mailto hyperlink.
+func (m model) contactView() string {
+	label, url := m.choiceLink(m.Choice)
+	title := m.aboutNameStyle.Render(label)
+	email := m.hyperlink(url, m.linkStyle.Render(copyText(url)))
+	hint := m.subtleStyle.Render("Ctrl/cmd + click the address to write me an email, or copy it.")
+	tpl := m.hintLine()
+
+	s := fmt.Sprintf("%s\n\n%s\n\n%s\n\n%s", title, email, hint, tpl)
+	return m.mainStyle.Render("\n" + s + "\n\n")
+}
+
+// copyText returns what is copied to the clipboard for url, the address
+// itself for mailto links.
+func copyText(url string) string {
+	return strings.TrimPrefix(url, "mailto:")
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// denylist is a set of blocked IPs and CIDR ranges loaded from a file, which
+// can be reloaded while the server is running.
+type denylist struct {
+	path string
+
+	mu   sync.RWMutex
+	nets []*net.IPNet
+}
+
+// loadDenylist reads the denylist at path.
+func loadDenylist(path string) (*denylist, error) {
+	d := &denylist{path: path}
+	if err := d.reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// reload re-reads the denylist file, keeping the current entries if the file
+// can't be parsed.
+func (d *denylist) reload() error {
+	f, err := os.Open(d.path)
+	if err != nil {
+		return fmt.Errorf("open denylist: %w", err)
+	}
+	defer f.Close() // nolint: errcheck
+
+	nets, err := parseDenylist(f)
+	if err != nil {
+		return fmt.Errorf("parse denylist %s: %w", d.path, err)
+	}
+	d.mu.Lock()
+	d.nets = nets
+	d.mu.Unlock()
+	log.Info("Loaded denylist", "path", d.path, "entries", len(nets))
+	return nil
+}
+
+// contains reports whether ip is blocked.
+func (d *denylist) contains(ip net.IP) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, n := range d.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDenylist parses one IP or CIDR range per line, ignoring blank lines
+// and # comments.
+func parseDenylist(r io.Reader) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		entry, _, _ := strings.Cut(sc.Text(), "#")
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("line %d: invalid IP %q", line, entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid CIDR %q", line, entry)
+		}
+		nets = append(nets, n)
+	}
+	return nets, sc.Err()
+}
+
+// denylistMiddleware rejects sessions from blocked IPs.
+func denylistMiddleware(d *denylist) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			ip := remoteIP(s)
+			if d.contains(net.ParseIP(ip)) {
+				log.Warn("Rejected denylisted connection", "remote", ip)
+				setResult(s, resultDenied)
+				wish.Fatalln(s, "Access denied.")
+				return
+			}
+			next(s)
+		}
+	}
+}
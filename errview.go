@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// errorMsg reports that an action of the view failed, what was tried being
+// told to the visitor and err traced, the caller logging it as it sees fit.
+// It's dropped if the visitor left the view in the meantime.
+type errorMsg struct {
+	view   viewState
+	action string // like "Couldn't load the projects"
+	err    error
+}
+
+// showError switches to the error view for msg.
+func (m model) showError(msg errorMsg) model {
+	if m.state != msg.view {
+		return m
+	}
+	m.traceEvent("error.show", attribute.String("error.action", msg.action), attribute.String("error.message", msg.err.Error()))
+	m.errorText = msg.action
+	m.state = stateError
+	return m
+}
+
+// updateError goes back to the menu.
+func (m model) updateError(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "backspace", "enter", "h", "left":
+		m.state = stateMenu
+	}
+	return m, nil
+}
+
+func (m model) errorView() string {
+	title := m.errorStyle.Render("Something went wrong")
+	tpl := m.hintLine()
+
+	s := fmt.Sprintf("%s\n\n%s\n%s\n\n%s", title, m.aboutStyle.Render(m.errorText+"."), m.subtleStyle.Render("Try again in a little while."), tpl)
+	return m.mainStyle.Render("\n" + s + "\n\n")
+}
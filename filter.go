@@ -0,0 +1,135 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const filterMaxQuery = 40
+
+// listFilter narrows the lists of the card to the entries matching a query
+// typed after "/". It's applied to the entries before they're paginated and
+// rendered, so the pagers index the matching entries only.
+type listFilter struct {
+	input   textinput.Model
+	editing bool
+}
+
+func newListFilter() listFilter {
+	input := textinput.New()
+	input.Prompt = "/"
+	input.Placeholder = "search"
+	input.CharLimit = filterMaxQuery
+	input.Width = filterMaxQuery
+	// The input doesn't get the blink messages, the lists handle their own.
+	input.Cursor.SetMode(cursor.CursorStatic)
+	return listFilter{input: input}
+}
+
+// query returns the lower cased query, empty when not filtering.
+func (f listFilter) query() string {
+	return strings.ToLower(strings.TrimSpace(f.input.Value()))
+}
+
+// Update starts typing a query on "/", applies it on enter and clears it on
+// esc. It reports whether the key was for the filter, or should be handled
+// by the list.
+func (f listFilter) Update(msg tea.KeyMsg) (listFilter, tea.Cmd, bool) {
+	if !f.editing {
+		switch msg.String() {
+		case "/":
+			f.editing = true
+			return f, f.input.Focus(), true
+		case "esc":
+			if f.query() != "" {
+				f.input.Reset()
+				return f, nil, true
+			}
+		}
+		return f, nil, false
+	}
+
+	switch msg.String() {
+	case "enter":
+		f.editing = false
+		f.input.Blur()
+		return f, nil, true
+	case "esc":
+		f.editing = false
+		f.input.Blur()
+		f.input.Reset()
+		return f, nil, true
+	}
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(msg)
+	return f, cmd, true
+}
+
+// indices returns the indices of the n entries matching the query, fields
+// returning the texts searched for the entry i.
+func (f listFilter) indices(n int, fields func(i int) []string) []int {
+	q := f.query()
+	matches := make([]int, 0, n)
+	for i := range n {
+		for _, text := range fields(i) {
+			if q == "" || strings.Contains(strings.ToLower(text), q) {
+				matches = append(matches, i)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// highlight renders s with style, and the parts of it matching the query
+// with match.
+func (f listFilter) highlight(s string, style, match lipgloss.Style) string {
+	q := f.query()
+	lower := strings.ToLower(s)
+	// Lower casing can change the length of some runes, their offsets
+	// wouldn't line up.
+	if q == "" || len(lower) != len(s) {
+		return style.Render(s)
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, q)
+		if i < 0 {
+			break
+		}
+		if i > 0 {
+			b.WriteString(style.Render(s[:i]))
+		}
+		b.WriteString(match.Render(s[i : i+len(q)]))
+		s, lower = s[i+len(q):], lower[i+len(q):]
+	}
+	if s != "" {
+		b.WriteString(style.Render(s))
+	}
+	return b.String()
+}
+
+// View renders the query being typed or applied, or nothing when not
+// filtering.
+func (f listFilter) View() string {
+	if !f.editing && f.query() == "" {
+		return ""
+	}
+	return f.input.View()
+}
+
+// hints returns the hints of a list using the filter, around the hints of
+// the list itself.
+func (f listFilter) hints(list ...string) []string {
+	switch {
+	case f.editing:
+		return []string{"enter: apply", "esc: clear"}
+	case f.query() != "":
+		return append(list, "/: search", "esc: clear", "q: quit")
+	}
+	return append(list, "/: search", "esc: back", "q: quit")
+}
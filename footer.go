@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/termenv"
+)
+
+// footerHeight is how many lines at the bottom of the window are taken by the
+// footer.
+const footerHeight = 1
+
+// startTime is when the server started, for the uptime in the footer.
+var startTime = time.Now()
+
+// bodyHeight is the height left to the views above the footer.
+func (m model) bodyHeight() int {
+	return m.Height - footerHeight
+}
+
+// withFooter pins the footer to the bottom of the window below view.
+func (m model) withFooter(view string) string {
+	body := lipgloss.PlaceVertical(m.bodyHeight(), lipgloss.Top, trimTrailingLines(view))
+	return body + "\n" + m.footer()
+}
+
+// footer renders the URL of the link selected in the menu, the size and
+// color profile of the client terminal along with the server uptime, the
+// recent connections, what I'm listening to and the weather where I am.
+func (m model) footer() string {
+	return m.subtleStyle.Copy().MarginLeft(2).MaxWidth(m.Width).Render(fmt.Sprintf("%s%dx%d%s%s%sup %s%s%s%s",
+		m.linkPreview(),
+		m.Width, m.Height,
+		m.glyphs.dot, profileName(m.colorProfile),
+		m.glyphs.dot, formatUptime(time.Since(startTime)),
+		m.historyText(),
+		m.nowPlayingText(),
+		m.weatherText(),
+	))
+}
+
+// linkPreview renders where the link selected in the menu leads, truncated
+// to half the width so the rest of the footer still shows.
+func (m model) linkPreview() string {
+	if m.state != stateMenu || m.showHelp || m.confirmingQuit {
+		return ""
+	}
+	_, url := m.choiceLink(m.Choice)
+	if url == "" {
+		return ""
+	}
+	prefix := m.glyphs.arrow + " "
+	url = runewidth.Truncate(url, max(m.Width/2-runewidth.StringWidth(prefix), 0), m.glyphs.ellipsis)
+	return prefix + url + m.glyphs.dot
+}
+
+func profileName(p termenv.Profile) string {
+	switch p {
+	case termenv.TrueColor:
+		return "truecolor"
+	case termenv.ANSI256:
+		return "256 colors"
+	case termenv.ANSI:
+		return "16 colors"
+	}
+	return "no colors"
+}
+
+// formatUptime formats d in days, hours and minutes.
+func formatUptime(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+	days := d / (24 * time.Hour)
+	hours := d % (24 * time.Hour) / time.Hour
+	minutes := d % time.Hour / time.Minute
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+// trimTrailingLines drops the empty lines at the end of s.
+func trimTrailingLines(s string) string {
+	for len(s) > 0 && s[len(s)-1] == '\n' {
+		s = s[:len(s)-1]
+	}
+	return s
+}
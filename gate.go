@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/ssh"
+)
+
+// waitForKey asks the visitor of session s to press a key before the card
+// starts, keeping out the bots which connect without sending anything until
+// the idle timeout closes them. It reports whether a key other than ctrl+c
+// was pressed.
+func waitForKey(s ssh.Session, renderer *lipgloss.Renderer, g glyphs) bool {
+	style := renderer.NewStyle().Foreground(themes[0].accent).MarginLeft(2)
+	fmt.Fprintf(crlfWriter{s}, "\n%s\n", style.Render("Press any key to continue"+g.ellipsis))
+	// Keys like the arrows send several bytes, which all go so the card
+	// doesn't get the rest of them.
+	b := make([]byte, 64)
+	if _, err := s.Read(b); err != nil || b[0] == 0x03 {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestWaitForKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"key", "x", true},
+		{"arrow", "\x1b[A", true},
+		{"ctrl+c", "\x03", false},
+		{"closed", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := strings.NewReader(tt.input)
+			s := &fakeSession{in: in}
+			if got := waitForKey(s, lipgloss.NewRenderer(io.Discard), glyphsFor("xterm")); got != tt.want {
+				t.Errorf("waitForKey = %t, want %t", got, tt.want)
+			}
+			if left, _ := io.ReadAll(in); len(left) > 0 {
+				t.Errorf("left %q for the card", left)
+			}
+		})
+	}
+}
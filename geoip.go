@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+
+	"github.com/charmbracelet/log"
+	"github.com/oschwald/geoip2-golang"
+)
+
+// geoIP looks up where visitors connect from in a MaxMind GeoLite2 City
+// database. It's opened once and shared by all sessions, a nil geoIP finds
+// nothing.
+type geoIP struct {
+	db *geoip2.Reader
+}
+
+// loadGeoIP opens the database at path. It returns nil without an error when
+// path is empty or the file doesn't exist, which hides the greeting.
+func loadGeoIP(path string) (*geoIP, error) {
+	if path == "" {
+		return nil, nil
+	}
+	db, err := geoip2.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Info("No GeoIP database, not greeting visitors from their city", "path", path)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &geoIP{db}, nil
+}
+
+// city returns the city ip is in, or its country when the city isn't known.
+// It returns an empty string for private addresses and failed lookups.
+func (g *geoIP) city(ip string) string {
+	addr := net.ParseIP(ip)
+	if g == nil || addr == nil || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() || addr.IsLinkLocalUnicast() {
+		return ""
+	}
+	rec, err := g.db.City(addr)
+	if err != nil {
+		return ""
+	}
+	name := rec.City.Names["en"]
+	if name == "" {
+		name = rec.Country.Names["en"]
+	}
+	return sanitize(name, guestbookMaxName)
+}
+
+func (g *geoIP) close() {
+	if g != nil {
+		g.db.Close()
+	}
+}
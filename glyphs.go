@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// glyphs are the symbols the card is drawn with besides text, so terminals
+// whose font lacks them get ASCII look-alikes instead of boxes.
+type glyphs struct {
+	dot       string // between hints and footer entries
+	checked   string
+	unchecked string
+	caret     []string // frames of the caret pulsing next to the choice
+	shade     string   // behind the help and quit boxes
+	scroll    string
+	external  string // after links opening in the browser
+	arrow     string // before the URL of the link selected, in the footer
+	star      string
+	music     string
+	dash      string
+	ellipsis  string
+	mail      string // before messages from admins
+	block     string
+	spark     []rune // bars of the sparkline, lowest first
+	spinner   spinner.Spinner
+	border    lipgloss.Border
+	// doubleBorder is drawn around the card when it's asked for.
+	doubleBorder lipgloss.Border
+	sky          [skyStorm + 1]string // by sky, in the weather widget
+	degree       string
+}
+
+var unicodeGlyphs = glyphs{
+	dot:       " • ",
+	checked:   "[x]",
+	unchecked: "[ ]",
+	caret:     []string{"·", "•", "●", "•"},
+	shade:     "░",
+	scroll:    "↕",
+	external:  "↗",
+	arrow:     "→",
+	star:      "★",
+	music:     "♫",
+	dash:      "–",
+	ellipsis:  "…",
+	mail:      "✉",
+	block:     "█",
+	spark:     []rune("▁▂▃▄▅▆▇█"),
+	spinner:   spinner.Dot,
+	border:    lipgloss.RoundedBorder(),
+
+	doubleBorder: lipgloss.DoubleBorder(),
+	sky:          [...]string{"☀", "☁", "≡", "☂", "❄", "ϟ"},
+	degree:       "°",
+}
+
+var asciiGlyphs = glyphs{
+	dot:       " * ",
+	checked:   "[x]",
+	unchecked: "[ ]",
+	caret:     []string{".", "o", "O", "o"},
+	shade:     " ",
+	scroll:    "|",
+	external:  "->",
+	arrow:     "->",
+	star:      "*",
+	music:     "~",
+	dash:      "-",
+	ellipsis:  "...",
+	mail:      "@",
+	block:     "#",
+	spark:     []rune("_.-=+*#"),
+	spinner:   spinner.Line,
+	border: lipgloss.Border{
+		Top: "-", Bottom: "-", Left: "|", Right: "|",
+		TopLeft: "+", TopRight: "+", BottomLeft: "+", BottomRight: "+",
+	},
+	doubleBorder: lipgloss.Border{
+		Top: "=", Bottom: "=", Left: "|", Right: "|",
+		TopLeft: "+", TopRight: "+", BottomLeft: "+", BottomRight: "+",
+	},
+	sky: [...]string{"sunny", "cloudy", "foggy", "rainy", "snowy", "stormy"},
+}
+
+// glyphsFor returns the glyphs for the client terminal, ASCII ones for the
+// consoles and old terminals whose fonts are usually limited to it.
+func glyphsFor(term string) glyphs {
+	switch {
+	case term == "", term == "dumb", term == "linux", term == "ansi", strings.HasPrefix(term, "vt"):
+		return asciiGlyphs
+	}
+	return unicodeGlyphs
+}
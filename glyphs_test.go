@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+// glyphStrings returns every string of v, walking through its fields,
+// slices and arrays.
+func glyphStrings(v reflect.Value) []string {
+	switch v.Kind() {
+	case reflect.String:
+		return []string{v.String()}
+	case reflect.Int32:
+		return []string{string(rune(v.Int()))}
+	case reflect.Struct:
+		var s []string
+		for i := range v.NumField() {
+			s = append(s, glyphStrings(v.Field(i))...)
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		var s []string
+		for i := range v.Len() {
+			s = append(s, glyphStrings(v.Index(i))...)
+		}
+		return s
+	}
+	return nil
+}
+
+func TestGlyphsFor(t *testing.T) {
+	tests := []struct {
+		term  string
+		ascii bool
+	}{
+		{"linux", true},
+		{"dumb", true},
+		{"vt100", true},
+		{"xterm-256color", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			var unicode []string
+			for _, s := range glyphStrings(reflect.ValueOf(glyphsFor(tt.term))) {
+				if len(s) != utf8.RuneCountInString(s) {
+					unicode = append(unicode, s)
+				}
+			}
+			if tt.ascii && len(unicode) > 0 {
+				t.Errorf("glyphs aren't ASCII: %q", unicode)
+			}
+			if !tt.ascii && len(unicode) == 0 {
+				t.Error("glyphs are all ASCII")
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+)
+
+// visitorLocation returns the timezone forwarded by the client in TZ, falling
+// back to the server timezone when it's missing or malformed. Clients only
+// send it with `SendEnv TZ` and a server accepting it.
+func visitorLocation(s ssh.Session) *time.Location {
+	for _, kv := range s.Environ() {
+		name, ok := strings.CutPrefix(kv, "TZ=")
+		if !ok || name == "" {
+			continue
+		}
+		// POSIX allows a leading colon before the zone name.
+		if loc, err := time.LoadLocation(strings.TrimPrefix(name, ":")); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// greeting returns the greeting of the about text, addressing the visitor by
+// name if they gave one.
+func (m model) greeting() string {
+	g := m.tr.greeting(time.Now().In(m.location))
+	if m.visitorName != "" {
+		g += " " + m.visitorName
+	}
+	return g
+}
+
+// newNameInput returns the prompt asking visitors for their name.
+func newNameInput() textinput.Model {
+	input := textinput.New()
+	input.Placeholder = "Anonymous"
+	input.CharLimit = guestbookMaxName
+	input.Width = guestbookMaxName
+	input.Focus()
+	return input
+}
+
+// updateName handles the name prompt, showing the card once the visitor
+// answered or skipped it.
+func (m model) updateName(msg tea.Msg) (model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "enter":
+			m.visitorName = sanitize(m.nameInput.Value(), guestbookMaxName)
+			fallthrough
+		case "esc":
+			m.state = stateMenu
+			if m.typing {
+				return m, typewriterTick()
+			}
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.nameInput, cmd = m.nameInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) nameView() string {
+	title := m.aboutNameStyle.Render("What's your name?")
+	tpl := m.hintLine()
+
+	s := fmt.Sprintf("%s\n\n%s\n\n%s", title, m.nameInput.View(), tpl)
+	return m.mainStyle.Render("\n" + s + "\n\n")
+}
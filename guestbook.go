@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	guestbookMaxEntries = 1000
+	guestbookMaxMessage = 140
+	guestbookMaxName    = 32
+	guestbookRecent     = 100
+	guestbookCooldown   = time.Minute
+)
+
+var errGuestbookCooldown = errors.New("you just signed, try again in a minute")
+
+type guestbookEntry struct {
+	Name    string    `json:"name"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// guestbook is the list of messages left by visitors, persisted as JSON.
+type guestbook struct {
+	path string
+
+	mu       sync.Mutex
+	entries  []guestbookEntry
+	lastSign map[string]time.Time
+}
+
+// loadGuestbook reads the guestbook from path, or its backup if it's
+// damaged, starting empty if the file doesn't exist yet.
+func loadGuestbook(path string) (*guestbook, error) {
+	g := &guestbook{path: path, lastSign: make(map[string]time.Time)}
+	err := loadStateFile(path, func(data []byte) error {
+		var entries []guestbookEntry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return err
+		}
+		g.entries = entries
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("load guestbook: %w", err)
+	}
+	return g, nil
+}
+
+// sign adds a message from the visitor at ip, allowing one message per IP
+// every guestbookCooldown.
+func (g *guestbook) sign(ip, name, message string) error {
+	name = sanitize(name, guestbookMaxName)
+	message = sanitize(message, guestbookMaxMessage)
+	if message == "" {
+		return errors.New("message is empty")
+	}
+	if name == "" {
+		name = "anonymous"
+	}
+
+	now := time.Now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if t, ok := g.lastSign[ip]; ok && now.Sub(t) < guestbookCooldown {
+		return errGuestbookCooldown
+	}
+	for k, t := range g.lastSign {
+		if now.Sub(t) >= guestbookCooldown {
+			delete(g.lastSign, k)
+		}
+	}
+
+	g.entries = append(g.entries, guestbookEntry{Name: name, Message: message, Time: now})
+	if len(g.entries) > guestbookMaxEntries {
+		g.entries = g.entries[len(g.entries)-guestbookMaxEntries:]
+	}
+	data, err := json.MarshalIndent(g.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(g.path, data, 0o644); err != nil {
+		return fmt.Errorf("save guestbook: %w", err)
+	}
+	g.lastSign[ip] = now
+	return nil
+}
+
+// recent returns up to n of the latest entries, newest first.
+func (g *guestbook) recent(n int) []guestbookEntry {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	n = min(n, len(g.entries))
+	entries := make([]guestbookEntry, 0, n)
+	for i := len(g.entries) - 1; i >= len(g.entries)-n; i-- {
+		entries = append(entries, g.entries[i])
+	}
+	return entries
+}
+
+// sanitize strips control and formatting characters, which covers escape
+// sequences and bidi overrides, so visitor input can't mess with the
+// terminals of other visitors. The result is truncated to max runes.
+func sanitize(s string, max int) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) || r == unicode.ReplacementChar {
+			continue
+		}
+		if n == max {
+			break
+		}
+		b.WriteRune(r)
+		n++
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// guestbookModel is the view where visitors read and sign the guestbook.
+type guestbookModel struct {
+	book   *guestbook
+	ip     string
+	name   string
+	input  textinput.Model
+	pages  pager
+	height int
+	result string
+
+	nameStyle  lipgloss.Style
+	textStyle  lipgloss.Style
+	mutedStyle lipgloss.Style
+}
+
+func newGuestbookModel(book *guestbook, ip, name string, nameStyle, textStyle, mutedStyle lipgloss.Style) guestbookModel {
+	input := textinput.New()
+	input.Placeholder = "Say hi..."
+	input.CharLimit = guestbookMaxMessage
+	input.Width = 50
+	input.Focus()
+
+	return guestbookModel{
+		book:       book,
+		ip:         ip,
+		name:       sanitize(name, guestbookMaxName),
+		input:      input,
+		pages:      newPagerWithKeys([]string{"up", "pgup"}, []string{"down", "pgdown"}),
+		nameStyle:  nameStyle,
+		textStyle:  textStyle,
+		mutedStyle: mutedStyle,
+	}
+}
+
+// withHeight fits the guestbook within height lines.
+func (g guestbookModel) withHeight(height int) guestbookModel {
+	g.height = height
+	return g.paginate()
+}
+
+// paginate fits the pages of the entries to the height of the guestbook,
+// the entries changing as visitors sign it.
+func (g guestbookModel) paginate() guestbookModel {
+	// Every entry takes two lines, below the input and above the page.
+	g.pages = g.pages.resize((g.height-6)/2, len(g.book.recent(guestbookRecent)))
+	return g
+}
+
+func (g guestbookModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (g guestbookModel) Update(msg tea.Msg) (guestbookModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "enter":
+			if err := g.book.sign(g.ip, g.name, g.input.Value()); err != nil {
+				g.result = "Couldn't sign: " + err.Error()
+			} else {
+				g.result = "Thanks for signing!"
+				g.input.Reset()
+				g.pages.selected = 0
+			}
+			return g.paginate(), nil
+		case "up", "down", "pgup", "pgdown":
+			var cmd tea.Cmd
+			g.pages, cmd = g.paginate().pages.Update(msg)
+			return g, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	g.input, cmd = g.input.Update(msg)
+	return g, cmd
+}
+
+func (g guestbookModel) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s\n", g.textStyle.Render("Leave a message as ")+g.nameStyle.Render(g.name)+g.textStyle.Render(":"), g.input.View())
+	if g.result != "" {
+		fmt.Fprintf(&b, "%s\n", g.mutedStyle.Render(g.result))
+	}
+	b.WriteString("\n")
+
+	entries := g.book.recent(guestbookRecent)
+	if len(entries) == 0 {
+		b.WriteString(g.mutedStyle.Render("No messages yet, be the first one!"))
+		return b.String()
+	}
+	pages := g.paginate().pages
+	start, end := pages.GetSliceBounds(len(entries))
+	for _, e := range entries[start:end] {
+		fmt.Fprintf(&b, "%s %s\n  %s\n",
+			g.nameStyle.Render(e.Name),
+			g.mutedStyle.Render(e.Time.Format("2006-01-02")),
+			g.textStyle.Render(e.Message),
+		)
+	}
+	if page := pages.View(); page != "" {
+		fmt.Fprintf(&b, "\n%s\n", g.mutedStyle.Render(page))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (m model) guestbookView() string {
+	title := m.aboutNameStyle.Render("Guestbook")
+	tpl := m.hintLine()
+
+	s := fmt.Sprintf("%s\n\n%s\n\n%s", title, m.guestbook.View(), tpl)
+	return m.mainStyle.Render("\n" + s + "\n\n")
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyBindings lists every key binding of the card, shown in the help overlay.
+var keyBindings = []struct{ key, desc string }{
+	{"j/k, up/down", "select / scroll"},
+	{"gg/G, 1-9", "jump to the first, last or nth"},
+	{"pgup/pgdown", "scroll a page"},
+	{"enter", "open the selected link"},
+	{"click", "open the clicked link"},
+	{"r", "show a qr code of the link"},
+	{"c", "copy the link to the clipboard"},
+	{"C", "copy all the links"},
+	{"o", "open the resume pdf"},
+	{"m", "sign the guestbook"},
+	{"w", "who's online"},
+	{"S", "server stats"},
+	{"p", "my github projects"},
+	{"i", "share this card"},
+	{"t", "switch the color theme"},
+	{"P", "toggle the plain mode"},
+	{"/", "search the blog or projects"},
+	{"esc", "go back"},
+	{"?", "toggle this help"},
+	{"q, ctrl+c", "quit"},
+}
+
+// helpView renders the key bindings in a box centered over a dimmed
+// background.
+func (m model) helpView() string {
+	var b strings.Builder
+	b.WriteString(m.aboutNameStyle.Render("Key bindings") + "\n\n")
+	for _, k := range keyBindings {
+		fmt.Fprintf(&b, "%s  %s\n", m.checkboxStyle.Render(padRight(k.key, 12)), m.aboutStyle.Render(k.desc))
+	}
+	b.WriteString("\n" + m.hintLine())
+
+	box := m.helpStyle.Render(b.String())
+	return lipgloss.Place(m.Width, m.bodyHeight(), lipgloss.Center, lipgloss.Center, box,
+		lipgloss.WithWhitespaceChars(m.glyphs.shade),
+		lipgloss.WithWhitespaceForeground(m.dimColor),
+	)
+}
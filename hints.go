@@ -0,0 +1,87 @@
+package main
+
+// keyHinter is a view, or the model behind it, listing the keys it handles
+// for the hint line at its bottom.
+type keyHinter interface {
+	keyHints() []string
+}
+
+// hints are the key hints of a view which always has the same ones.
+type hints []string
+
+func (h hints) keyHints() []string { return h }
+
+func (a adminModel) keyHints() []string {
+	if a.to != "" {
+		return []string{"enter: send", "esc: cancel"}
+	}
+	return []string{"j/k: select", "m: message", "M: message all", "x: kick", "esc: card", "q: quit"}
+}
+
+func (g guestbookModel) keyHints() []string {
+	return []string{"enter: sign", "up/down: page", "esc: back", "ctrl+c: quit"}
+}
+
+func (s snakeModel) keyHints() []string {
+	return []string{"arrows, hjkl, wasd: turn", "esc: back", "ctrl+c: quit"}
+}
+
+// hinter returns what lists the keys of what's on screen, the overlays over
+// the view first.
+func (m model) hinter() keyHinter {
+	switch {
+	case m.confirmingQuit:
+		return hints{"y: quit", "n, esc: back"}
+	case m.showHelp:
+		return hints{"?, esc: close"}
+	}
+
+	switch m.state {
+	case stateLink:
+		return hints{"esc: back", "r: qr code", "c: copy", "q, ctrl+c: quit"}
+	case stateQR:
+		return hints{"esc: back", "c: copy", "q, ctrl+c: quit"}
+	case stateShare:
+		return hints{"c: copy", "esc: back", "q: quit"}
+	case stateContact:
+		return hints{"esc: back", "c: copy", "r: qr code", "q, ctrl+c: quit"}
+	case stateGuestbook:
+		return m.guestbook
+	case stateSnake:
+		return m.snake
+	case stateAdmin:
+		return m.admin
+	case stateName:
+		return hints{"enter: continue", "esc: skip", "ctrl+c: quit"}
+	case stateResume:
+		return hints{"j/k: scroll", "o: open the pdf", "esc: back", "q: quit"}
+	case stateChangelog:
+		return hints{"j/k: scroll", "esc: back", "q: quit"}
+	case statePost:
+		return hints{"j/k: scroll", "esc: back", "q: quit"}
+	case stateBlog:
+		if len(m.posts) == 0 {
+			return hints{"esc: back", "q: quit"}
+		}
+		list := []string{"j/k: select", "enter: read"}
+		if m.blogPages.TotalPages > 1 {
+			list = append(list, "h/l: page")
+		}
+		return hints(m.filter.hints(list...))
+	case stateProjects:
+		var list []string
+		if m.projectPages.TotalPages > 1 {
+			list = append(list, "h/l: page")
+		}
+		return hints(m.filter.hints(list...))
+	case stateOnline, stateStats, stateError, stateSkills:
+		return hints{"esc: back", "q: quit"}
+	}
+	return hints{"j/k: select", "enter: open", "?: help", "q: quit"}
+}
+
+// hintLine renders the hint line of what's on screen, followed by extra
+// information.
+func (m model) hintLine(extra ...string) string {
+	return m.hint(append(m.hinter().keyHints(), extra...)...)
+}
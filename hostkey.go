@@ -0,0 +1,86 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// hostKeyNames are the host key files looked up in the host key directory.
+// The ed25519 key is always present, the others are loaded when they exist so
+// older clients negotiating e.g. RSA only can still connect.
+var hostKeyNames = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// hostKeyOptions returns a server option for every host key found in dir,
+// generating the ed25519 key if it's missing.
+func hostKeyOptions(dir string) ([]ssh.Option, error) {
+	if err := ensureHostKey(filepath.Join(dir, hostKeyNames[0])); err != nil {
+		return nil, err
+	}
+
+	var opts []ssh.Option
+	var algorithms []string
+	for _, name := range hostKeyNames {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read host key %s: %w", path, err)
+		}
+		signer, err := gossh.ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse host key %s: %w", path, err)
+		}
+		opts = append(opts, wish.WithHostKeyPath(path))
+		algorithms = append(algorithms, signer.PublicKey().Type())
+	}
+	log.Info("Loaded host keys", "dir", dir, "algorithms", algorithms)
+	return opts, nil
+}
+
+// ensureHostKey makes sure an ed25519 host key exists at path, generating one
+// when the file is missing. An existing key is never replaced as that would
+// change the server fingerprint, an error is returned instead if it can't be
+// read or parsed.
+func ensureHostKey(path string) error {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if _, err := gossh.ParsePrivateKey(data); err != nil {
+			return fmt.Errorf("parse host key %s: %w", path, err)
+		}
+		log.Info("Using existing host key", "path", path)
+		return nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read host key %s: %w", path, err)
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generate host key: %w", err)
+	}
+	block, err := gossh.MarshalPrivateKey(key, "")
+	if err != nil {
+		return fmt.Errorf("marshal host key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create host key directory: %w", err)
+	}
+	if err := writeFileAtomic(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		return fmt.Errorf("write host key %s: %w", path, err)
+	}
+	log.Info("Generated new host key", "path", path)
+	return nil
+}
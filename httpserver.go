@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// ready reports whether the SSH server is accepting connections, it's unset
+// again as soon as the server starts shutting down.
+var ready atomic.Bool
+
+// healthz answers probes with 200 while the SSH server is ready, 503
+// otherwise.
+func healthz(w http.ResponseWriter, _ *http.Request) {
+	if !ready.Load() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// startHTTPServers serves the metrics and health endpoints which are enabled
+// in cfg, sharing a server when they're on the same address.
+func startHTTPServers(cfg Config) []*http.Server {
+	muxes := make(map[string]*http.ServeMux)
+	mux := func(addr string) *http.ServeMux {
+		if muxes[addr] == nil {
+			muxes[addr] = http.NewServeMux()
+		}
+		return muxes[addr]
+	}
+	if cfg.MetricsAddr != "" {
+		mux(cfg.MetricsAddr).Handle("/metrics", promhttp.Handler())
+	}
+	if cfg.HealthAddr != "" {
+		mux(cfg.HealthAddr).HandleFunc("/healthz", healthz)
+	}
+
+	servers := make([]*http.Server, 0, len(muxes))
+	for addr, mux := range muxes {
+		srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			log.Info("Starting HTTP server", "addr", addr)
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error("Could not start HTTP server", "addr", addr, "error", err)
+			}
+		}()
+		servers = append(servers, srv)
+	}
+	return servers
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// sessionEnv returns the value of the variable key in the environment
+// forwarded by the client, or an empty string if it's not there.
+func sessionEnv(environ []string, key string) string {
+	for _, kv := range environ {
+		if v, ok := strings.CutPrefix(kv, key+"="); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// supportsHyperlinks reports whether the client terminal is known to handle
+// OSC 8 hyperlinks. Besides TERM, clients only forward the variables telling
+// terminals apart with SendEnv.
+func supportsHyperlinks(term string, environ []string) bool {
+	switch term {
+	case "xterm-kitty", "xterm-ghostty", "wezterm", "foot", "contour":
+		return true
+	}
+	switch sessionEnv(environ, "TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "Hyper", "ghostty":
+		return true
+	}
+	if sessionEnv(environ, "WT_SESSION") != "" {
+		return true
+	}
+	// VTE based terminals, like GNOME Terminal, since 0.50.
+	v, err := strconv.Atoi(sessionEnv(environ, "VTE_VERSION"))
+	return err == nil && v >= 5000
+}
+
+// hyperlink makes text a hyperlink to url, except in plain mode and on
+// terminals without colors, which can't be expected to skip the escape
+// sequence either.
+func (m model) hyperlink(url, text string) string {
+	if m.plain || m.renderer.ColorProfile() == termenv.Ascii {
+		return text
+	}
+	return termenv.Hyperlink(url, text)
+}
+
+// linkLine makes line a hyperlink to url when the terminal supports them and
+// it fits in width. Lines are measured including the url, which the renderer
+// would otherwise cut when it seems wider than the window.
+func (m model) linkLine(line, url string, width int) string {
+	if !m.hyperlinks || m.plain || url == "" || m.renderer.ColorProfile() == termenv.Ascii {
+		return line
+	}
+	if linked := termenv.Hyperlink(url, line); lipgloss.Width(linked) <= width {
+		return linked
+	}
+	return line
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// warnIdle tells the visitor they'll be disconnected for inactivity in lead,
+// until they press a key.
+func (m model) warnIdle(lead time.Duration) (model, tea.Cmd) {
+	m, cmd := m.setStatusFor(fmt.Sprintf("Disconnecting for inactivity in %s %s press any key to stay", formatLead(lead), m.glyphs.dash), lead)
+	m.idleWarning = m.statusID
+	return m, cmd
+}
+
+// clearIdleWarning hides the idle warning once the visitor is back, unless
+// another status replaced it.
+func (m model) clearIdleWarning() model {
+	if m.idleWarning != 0 && m.idleWarning == m.statusID {
+		m.status = ""
+	}
+	m.idleWarning = 0
+	return m
+}
+
+// formatLead formats d in whole seconds, leaving out the zero seconds of
+// whole minutes.
+func formatLead(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d >= time.Minute && d%time.Minute == 0 {
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return d.String()
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// languageColors are the colors the languages are highlighted with, close
+// to their logos. Languages left out keep the color of the text around them.
+var languageColors = map[string]lipgloss.CompleteColor{
+	"C":          color("#a8b9cc", "146", "7"),
+	"C++":        color("#f34b7d", "204", "13"),
+	"Dart":       color("#00b4ab", "37", "6"),
+	"Go":         color("#00add8", "38", "14"),
+	"Java":       color("#e76f00", "166", "3"),
+	"Javascript": color("#f7df1e", "220", "11"),
+	"Kotlin":     color("#a97bff", "141", "13"),
+	"Python":     color("#4b8bbe", "68", "12"),
+	"Rust":       color("#dea584", "180", "3"),
+	"Shell":      color("#89e051", "113", "10"),
+	"Swift":      color("#f05138", "203", "9"),
+	"Typescript": color("#3178c6", "32", "4"),
+}
+
+// languagePattern matches the names of languageColors as whole words, the
+// longest first so C++ isn't taken for C.
+var languagePattern = func() *regexp.Regexp {
+	names := make([]string, 0, len(languageColors))
+	for name := range languageColors {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	return regexp.MustCompile(`\b(` + strings.Join(names, "|") + `)(\W|$)`)
+}()
+
+// languageStyle returns base in the color of language, or base itself for
+// the languages without one. GitHub spells some languages differently, so
+// they're matched case insensitively.
+func languageStyle(base lipgloss.Style, language string) lipgloss.Style {
+	for name, c := range languageColors {
+		if strings.EqualFold(name, language) {
+			return base.Copy().Foreground(c)
+		}
+	}
+	return base
+}
+
+// colorLanguages renders s in base, the names of the languages in their
+// color, leaving the wrapping to the caller. Every part is rendered on its
+// own as a style ends with a reset, which would otherwise leave the text
+// after a language unstyled.
+func colorLanguages(base lipgloss.Style, s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range languagePattern.FindAllStringSubmatchIndex(s, -1) {
+		start, end := loc[2], loc[3]
+		b.WriteString(renderLines(base, s[last:start]))
+		b.WriteString(languageStyle(base, s[start:end]).Render(s[start:end]))
+		last = end
+	}
+	b.WriteString(renderLines(base, s[last:]))
+	return b.String()
+}
+
+// renderLines renders every line of s in style on its own, as lipgloss would
+// pad them all to the width of the longest.
+func renderLines(style lipgloss.Style, s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = style.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
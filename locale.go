@@ -0,0 +1,171 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// localesFS holds the English text of the card. Translations are read from
+// SSH_LOCALES_DIR, a JSON file per locale named after it, like de.json or
+// pt_BR.json.
+//
+//go:embed locales
+var localesFS embed.FS
+
+const defaultLocale = "en"
+
+// translation is the text of the card in one language. Only the about text,
+// greetings and menu labels are translated, my name and the URLs stay as
+// they are.
+type translation struct {
+	// About has %s verbs for the greeting and my name.
+	About string `json:"about"`
+	// Greetings are keyed by part of the day: morning, afternoon and
+	// evening.
+	Greetings map[string]string `json:"greetings"`
+	// HelloFrom has a %s verb for the city of the visitor.
+	HelloFrom string `json:"hello_from"`
+	// Labels are keyed by the English label of the menu item.
+	Labels map[string]string `json:"labels"`
+}
+
+// locales are the translations by lower cased locale name.
+type locales map[string]translation
+
+// loadLocales reads the translations in the locales directory of fsys, then
+// those in dir if it's set. What a translation leaves out is taken from the
+// English one, which must be complete.
+func loadLocales(fsys fs.FS, dir string) (locales, error) {
+	l := make(locales)
+	if err := l.read(fsys, "locales/*.json"); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := l.read(os.DirFS(dir), "*.json"); err != nil {
+			return nil, err
+		}
+	}
+
+	en, ok := l[defaultLocale]
+	if !ok || en.About == "" || en.HelloFrom == "" {
+		return nil, fmt.Errorf("parse locale %s: missing or incomplete", defaultLocale)
+	}
+	for _, part := range []string{"morning", "afternoon", "evening"} {
+		if en.Greetings[part] == "" {
+			return nil, fmt.Errorf("parse locale %s: no %s greeting", defaultLocale, part)
+		}
+	}
+	for key, t := range l {
+		if t.About == "" {
+			t.About = en.About
+		}
+		if t.HelloFrom == "" {
+			t.HelloFrom = en.HelloFrom
+		}
+		t.Greetings = withFallback(t.Greetings, en.Greetings)
+		t.Labels = withFallback(t.Labels, en.Labels)
+		l[key] = t
+	}
+	return l, nil
+}
+
+// read adds the translations in the files of fsys matching pattern.
+func (l locales) read(fsys fs.FS, pattern string) error {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("read locale: %w", err)
+		}
+		var t translation
+		if err := json.Unmarshal(data, &t); err != nil {
+			return fmt.Errorf("parse locale %s: %w", name, err)
+		}
+		if n := strings.Count(t.About, "%s"); t.About != "" && n != 2 {
+			return fmt.Errorf("parse locale %s: about has %d %%s verbs instead of 2", name, n)
+		}
+		if n := strings.Count(t.HelloFrom, "%s"); t.HelloFrom != "" && n != 1 {
+			return fmt.Errorf("parse locale %s: hello_from has %d %%s verbs instead of 1", name, n)
+		}
+		l[localeKey(strings.TrimSuffix(path.Base(name), ".json"))] = t
+	}
+	return nil
+}
+
+// withFallback returns the entries of m, along with those of fallback it
+// doesn't have.
+func withFallback(m, fallback map[string]string) map[string]string {
+	merged := make(map[string]string, len(fallback))
+	for k, v := range fallback {
+		merged[k] = v
+	}
+	for k, v := range m {
+		if v != "" {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// localeKey normalizes a locale name, so en_US, en-us and en_US.UTF-8 are
+// the same.
+func localeKey(name string) string {
+	name, _, _ = strings.Cut(name, ".")
+	name, _, _ = strings.Cut(name, "@")
+	return strings.ToLower(strings.ReplaceAll(name, "-", "_"))
+}
+
+// english returns the English translation.
+func (l locales) english() translation {
+	return l[defaultLocale]
+}
+
+// forEnv returns the translation for the locale of the client, as forwarded
+// in LC_ALL, LC_MESSAGES or LANG with SendEnv. Regional locales fall back to
+// their language, and unknown ones to English.
+func (l locales) forEnv(environ []string) translation {
+	var name string
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if name = sessionEnv(environ, key); name != "" {
+			break
+		}
+	}
+	key := localeKey(name)
+	if t, ok := l[key]; ok {
+		return t
+	}
+	lang, _, _ := strings.Cut(key, "_")
+	if t, ok := l[lang]; ok {
+		return t
+	}
+	return l.english()
+}
+
+// greeting returns the greeting for the time of day at now.
+func (t translation) greeting(now time.Time) string {
+	switch h := now.Hour(); {
+	case h >= 5 && h < 12:
+		return t.Greetings["morning"]
+	case h >= 12 && h < 18:
+		return t.Greetings["afternoon"]
+	}
+	return t.Greetings["evening"]
+}
+
+// label returns the translation of the menu label, or label itself if it
+// has none.
+func (t translation) label(label string) string {
+	if s, ok := t.Labels[label]; ok {
+		return s
+	}
+	return label
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadLocalesFromDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "de.json"), []byte(`{"labels":{"Blog":"Tagebuch"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := loadLocales(localesFS, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	de := l.forEnv([]string{"LANG=de_DE.UTF-8"})
+	if got := de.label("Blog"); got != "Tagebuch" {
+		t.Errorf("Blog is %q, want Tagebuch", got)
+	}
+	if de.About != l.english().About {
+		t.Errorf("about is %q, want the English one", de.About)
+	}
+	if got := l.forEnv([]string{"LANG=fr_FR.UTF-8"}).label("Blog"); got != "Blog" {
+		t.Errorf("Blog is %q without a French translation, want Blog", got)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// logSampler lets the sessions of an IP be logged at most once per interval,
+// so a scanner opening connections in a loop doesn't flood the logs. The
+// sessions left out are counted, and reported with the next one logged. A nil
+// logSampler logs everything.
+type logSampler struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	seen map[string]*sampledIP
+}
+
+type sampledIP struct {
+	logged     time.Time
+	suppressed int
+}
+
+// newLogSampler returns a logSampler which periodically forgets the IPs
+// logged more than interval ago, reporting what it suppressed for them.
+func newLogSampler(interval time.Duration) *logSampler {
+	l := &logSampler{interval: interval, seen: make(map[string]*sampledIP)}
+	go func() {
+		for range time.Tick(interval) {
+			l.cleanup()
+		}
+	}()
+	return l
+}
+
+// sample reports whether the session from ip should be logged, along with
+// how many sessions from it weren't since the last one that was.
+func (l *logSampler) sample(ip string) (bool, int) {
+	if l == nil {
+		return true, 0
+	}
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.seen[ip]
+	if ok && now.Sub(s.logged) < l.interval {
+		s.suppressed++
+		return false, 0
+	}
+	suppressed := 0
+	if ok {
+		suppressed = s.suppressed
+	}
+	l.seen[ip] = &sampledIP{logged: now}
+	return true, suppressed
+}
+
+func (l *logSampler) cleanup() {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ip, s := range l.seen {
+		if now.Sub(s.logged) < l.interval {
+			continue
+		}
+		if s.suppressed > 0 {
+			log.Info("Sessions not logged", "ip", ip, "suppressed", s.suppressed)
+		}
+		delete(l.seen, ip)
+	}
+}
@@ -3,14 +3,19 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"net"
 	"os"
 	"os/signal"
+	"runtime"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
@@ -18,78 +23,493 @@ import (
 	"github.com/charmbracelet/wish"
 	"github.com/charmbracelet/wish/activeterm"
 	"github.com/charmbracelet/wish/bubbletea"
-	"github.com/charmbracelet/wish/logging"
+	"github.com/muesli/termenv"
+	"github.com/pires/go-proxyproto"
+	"go.opentelemetry.io/otel/attribute"
+	gossh "golang.org/x/crypto/ssh"
 )
 
-const (
-	host = "0.0.0.0"
-	port = "22"
+// Build information, set via -ldflags "-X main.version=... -X main.commit=...".
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
 )
 
 func main() {
-	s, err := wish.NewServer(
-		wish.WithAddress(net.JoinHostPort(host, port)),
-		wish.WithHostKeyPath(".ssh/id_ed25519"),
-		wish.WithMiddleware(
-			bubbletea.Middleware(teaHandler),
-			activeterm.Middleware(), // Bubble Tea apps usually require a PTY.
-			logging.Middleware(),
-		),
-	)
+	showVersion := flag.Bool("version", false, "print version information and exit")
+	flag.Parse()
+	if *showVersion {
+		printVersion()
+		return
+	}
+
+	cfg, err := loadConfig()
 	if err != nil {
-		log.Error("Could not start server", "error", err)
+		log.Error("Invalid configuration", "error", err)
+		os.Exit(1)
 	}
+	if cfg.LogFormat == "json" {
+		log.SetFormatter(log.JSONFormatter)
+	}
+	setEastAsianWidth(cfg.EastAsianWidth)
 
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-	log.Info("Starting SSH server", "host", host, "port", port)
+	hostKeys, err := hostKeyOptions(cfg.HostKeyDir)
+	if err != nil {
+		log.Error("Could not load host keys", "error", err)
+		os.Exit(1)
+	}
+
+	visitors, err := loadVisitorCounter(cfg.VisitorsFile, visitorDebounce)
+	if err != nil {
+		log.Error("Could not load visitor count", "error", err)
+		os.Exit(1)
+	}
+	book, err := loadGuestbook(cfg.GuestbookFile)
+	if err != nil {
+		log.Error("Could not load guestbook", "error", err)
+		os.Exit(1)
+	}
+	prefs, err := loadPrefStore(cfg.PrefsFile)
+	if err != nil {
+		log.Error("Could not load prefs", "error", err)
+		os.Exit(1)
+	}
+	if cfg.ThemeFile != "" {
+		if themes, err = loadThemes(cfg.ThemeFile); err != nil {
+			log.Error("Could not load themes", "error", err)
+			os.Exit(1)
+		}
+	}
+	if cfg.Theme != "" && themeIndex(cfg.Theme) < 0 {
+		log.Error("Unknown SSH_THEME", "theme", cfg.Theme)
+		os.Exit(1)
+	}
+	posts, err := loadPosts(postsFS)
+	if err != nil {
+		log.Error("Could not load blog posts", "error", err)
+		os.Exit(1)
+	}
+	locales, err := loadLocales(localesFS, cfg.LocalesDir)
+	if err != nil {
+		log.Error("Could not load translations", "error", err)
+		os.Exit(1)
+	}
+	projects := newProjectCache(cfg.GitHubUser)
+	go projects.get(context.Background()) // Warm the cache so the first visitor doesn't wait.
+	links, err := loadLinks(cfg.LinksFile)
+	if err != nil {
+		log.Error("Could not load links", "error", err)
+		os.Exit(1)
+	}
+	skills, err := loadSkills(cfg.SkillsFile)
+	if err != nil {
+		log.Error("Could not load skills", "error", err)
+		os.Exit(1)
+	}
+	changelog, err := loadChangelog(cfg.ChangelogFile)
+	if err != nil {
+		log.Error("Could not load changelog", "error", err)
+		os.Exit(1)
+	}
+	items := &itemStore{items: newMenuItems(cfg.ContactEmail, posts, skills, changelog, links)}
+	taglines, err := loadTaglines(cfg.TaglinesFile)
+	if err != nil {
+		log.Error("Could not load taglines", "error", err)
+		os.Exit(1)
+	}
+	tagStore := &taglineStore{taglines: taglines}
+	avatar, err := loadAvatar(cfg.AvatarFile)
+	if err != nil {
+		log.Error("Could not load avatar", "error", err)
+		os.Exit(1)
+	}
+	spotify := newSpotifyClient(cfg.SpotifyClientID, cfg.SpotifyClientSecret, cfg.SpotifyRefreshToken)
+	weather := newWeatherClient(cfg)
+	geo, err := loadGeoIP(cfg.GeoIPDB)
+	if err != nil {
+		log.Warn("Could not load GeoIP database, not greeting visitors from their city", "error", err)
+	}
+	defer geo.close()
+	a := &app{cfg: cfg, visitors: visitors, guestbook: book, online: newSessionRegistry(), prefs: prefs, projects: projects, posts: posts, skills: skills, changelog: changelog, items: items, spotify: spotify, weather: weather, geo: geo, locales: locales, history: &connectionHistory{}, taglines: tagStore, avatar: avatar, maintenance: newMaintenanceMode(cfg), config: &configStore{cfg: cfg}}
+	if cfg.RestoreView {
+		a.views = newViewTokens(cfg.RestoreTTL)
+	}
+
+	limiter := newRateLimiter(cfg.RateLimit, time.Minute)
+	var deny *denylist
+	if cfg.Denylist != "" {
+		if deny, err = loadDenylist(cfg.Denylist); err != nil {
+			log.Error("Could not load denylist", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	if cfg.RecordDir != "" {
+		if err := os.MkdirAll(cfg.RecordDir, 0o700); err != nil {
+			log.Error("Could not create recording directory", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	if cfg.AccessLog != "" {
+		a.accessLog = newAccessLog(cfg.AccessLog)
+	}
+
+	cmds := newCommands(items, locales.english())
+	middleware, guards := a.buildMiddleware(cmds, limiter, deny)
+
+	files, err := newSFTPFS(cfg.ResumePDF, cmds)
+	if err != nil {
+		log.Error("Could not load sftp files", "error", err)
+		os.Exit(1)
+	}
+
+	r := &reloader{cfg: cfg, config: a.config, posts: posts, skills: skills, changes: changelog, links: links, deny: deny, limiter: limiter, items: items, taglines: tagStore, cmds: cmds, files: files, maintenance: a.maintenance, online: a.online, accessLog: a.accessLog}
+	// The banner is printed by the client during authentication, before the
+	// session and its alt screen start.
+	banner, err := readBanner(cfg)
+	if err != nil {
+		log.Error("Could not read banner", "error", err)
+		os.Exit(1)
+	}
+	r.banner.Store(banner)
+
+	opts := append([]ssh.Option{
+		wish.WithMiddleware(middleware...),
+		wish.WithBannerHandler(r.bannerHandler),
+		wish.WithSubsystem("sftp", ssh.SubsystemHandler(chain(files.handler, guards))),
+		// Accept every client, asking for a public key only lets us tell
+		// returning visitors apart. Clients without keys fall back to
+		// keyboard-interactive without being prompted for anything.
+		wish.WithPublicKeyAuth(func(ssh.Context, ssh.PublicKey) bool { return true }),
+		// A key the client only offered without proving it owns it is left
+		// in the context, forget it so it can't pass for an admin key.
+		wish.WithKeyboardInteractiveAuth(func(ctx ssh.Context, _ gossh.KeyboardInteractiveChallenge) bool {
+			ctx.SetValue(ssh.ContextKeyPublicKey, nil)
+			return true
+		}),
+	}, hostKeys...)
+	// One server per address, sharing the middleware and host keys.
+	servers := make([]*ssh.Server, 0, len(cfg.Listen))
+	for _, addr := range cfg.Listen {
+		s, err := wish.NewServer(append([]ssh.Option{wish.WithAddress(addr)}, opts...)...)
+		if err != nil {
+			log.Error("Could not start server", "addr", addr, "error", err)
+			os.Exit(1)
+		}
+		servers = append(servers, s)
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
 	go func() {
-		if err = s.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
-			log.Error("Could not start server", "error", err)
-			done <- nil
+		for range hup {
+			r.reload()
 		}
 	}()
 
+	httpServers := startHTTPServers(cfg)
+	stopTracing, err := setupTracing(context.Background())
+	if err != nil {
+		log.Error("Could not set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	done := make(chan os.Signal, 1)
+	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	log.Info("Starting SSH server", "addrs", cfg.Listen, "shutdown_timeout", cfg.ShutdownTimeout)
+	if on, _ := a.maintenance.active(); on {
+		log.Warn("Maintenance mode is on, visitors won't see the card")
+	}
+	listeners := make([]net.Listener, len(servers))
+	for i, s := range servers {
+		if listeners[i], err = net.Listen("tcp", s.Addr); err != nil {
+			log.Error("Could not start server", "addr", s.Addr, "error", err)
+			os.Exit(1)
+		}
+		// Connections are rewritten to the client address before the
+		// handshake, so every middleware sees it. The header is required,
+		// clients connecting directly could otherwise spoof it.
+		if cfg.ProxyProtocol {
+			listeners[i] = &proxyproto.Listener{
+				Listener: listeners[i],
+				Policy:   func(net.Addr) (proxyproto.Policy, error) { return proxyproto.REQUIRE, nil },
+			}
+		}
+	}
+	ready.Store(true)
+	for i, s := range servers {
+		go func() {
+			if err := s.Serve(listeners[i]); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+				log.Error("Could not start server", "addr", s.Addr, "error", err)
+				done <- nil
+			}
+		}()
+	}
+
 	<-done
 	log.Info("Stopping SSH server")
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ready.Store(false)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer func() { cancel() }()
-	if err := s.Shutdown(ctx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
-		log.Error("Could not stop server", "error", err)
+	a.online.broadcast(ctx, shutdownMsg{})
+	var stopErr error
+	for _, s := range servers {
+		if err := s.Shutdown(ctx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+			stopErr = errors.Join(stopErr, fmt.Errorf("%s: %w", s.Addr, err))
+		}
+	}
+	if stopErr != nil {
+		log.Error("Could not stop server", "error", stopErr)
+	}
+	for _, srv := range httpServers {
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Error("Could not stop HTTP server", "addr", srv.Addr, "error", err)
+		}
+	}
+	if a.accessLog != nil {
+		if err := a.accessLog.Close(); err != nil {
+			log.Error("Could not close access log", "error", err)
+		}
+	}
+	if err := stopTracing(ctx); err != nil {
+		log.Error("Could not flush traces", "error", err)
+	}
+}
+
+// printVersion prints the build information, one field per line.
+func printVersion() {
+	fmt.Printf("version: %s\ncommit: %s\ndate: %s\ngo: %s\n", version, commit, date, runtime.Version())
+}
+
+// buildMiddleware returns the middleware of the sessions, leaving out the
+// one disabled in the config, along with the guards among them. The guards
+// are shared with the sftp subsystem, which doesn't go through the regular
+// middleware. deny is nil without a denylist.
+func (a *app) buildMiddleware(cmds commands, limiter *rateLimiter, deny *denylist) (middleware, guards []wish.Middleware) {
+	on := a.cfg.Middleware
+	if on.IdleTimeout {
+		guards = append(guards, idleTimeoutMiddleware(a.cfg.IdleTimeout, a.cfg.IdleWarning, a.online))
+	}
+	guards = append(guards,
+		maxDurationMiddleware(a.cfg.MaxDuration),
+		maxSessionsMiddleware(a.cfg.MaxSessions),
+		maxSessionsPerIPMiddleware(a.cfg.MaxSessionsPerIP),
+	)
+	if on.RateLimit {
+		guards = append(guards, rateLimitMiddleware(limiter))
+	}
+	if deny != nil {
+		guards = append(guards, denylistMiddleware(deny))
+	}
+	guards = append(guards, metricsMiddleware(), tracingMiddleware())
+	if on.Logging {
+		var sampler *logSampler
+		if a.cfg.LogSampleInterval > 0 {
+			sampler = newLogSampler(a.cfg.LogSampleInterval)
+		}
+		var rdns *reverseDNS
+		if a.cfg.ReverseDNS {
+			rdns = newReverseDNS()
+		}
+		guards = append(guards, logMiddleware(sampler, rdns))
+	}
+	if a.accessLog != nil {
+		guards = append(guards, accessLogMiddleware(a.accessLog))
+	}
+	if on.Recover {
+		guards = append(guards, recoverMiddleware()) // Keep last so it wraps every other middleware.
+	}
+
+	middleware = []wish.Middleware{teaMiddleware(a.programHandler, a.programExited)}
+	if on.ActiveTerm {
+		middleware = append(middleware, activeterm.Middleware()) // Bubble Tea apps usually require a PTY.
+	}
+	middleware = append(middleware,
+		recordMiddleware(a.cfg.RecordDir),
+		commandMiddleware(cmds, a.adminCommands(), a.isAdmin, a.writeCard),
+		registryMiddleware(a.online),
+		visitorMiddleware(a.visitors),
+		historyMiddleware(a.history),
+	)
+	return append(middleware, guards...), guards
+}
+
+// chain wraps h with mw the same way wish.WithMiddleware does, the last
+// middleware being the outermost.
+func chain(h ssh.Handler, mw []wish.Middleware) ssh.Handler {
+	for _, m := range mw {
+		h = m(h)
+	}
+	return h
+}
+
+// app holds the state shared by all sessions.
+type app struct {
+	cfg       Config
+	visitors  *visitorCounter
+	guestbook *guestbook
+	online    *sessionRegistry
+	prefs     *prefStore
+	projects  *projectCache
+	posts     []post
+	skills    []skill
+	changelog string // markdown
+	items     *itemStore
+	spotify   *spotifyClient
+	weather   *weatherClient
+	geo       *geoIP
+	locales   locales
+	history   *connectionHistory
+	views     *viewTokens
+	taglines  *taglineStore
+	avatar    []byte // nil unless SSH_AVATAR_FILE is set
+	// maintenance keeps new visitors out while it's on.
+	maintenance *maintenanceMode
+	// config is the config in effect, cfg being the one the server started
+	// with.
+	config *configStore
+	// accessLog is nil unless SSH_ACCESS_LOG is set.
+	accessLog *accessLog
+}
+
+// programHandler starts the Bubble Tea program of a session and registers it,
+// so it can be told when the server shuts down. It returns nil for sessions
+// without a PTY.
+func (a *app) programHandler(s ssh.Session) *tea.Program {
+	// The renderer of the program and the clipboard writes share the session.
+	s = &syncSession{Session: s}
+	m, opts := a.teaHandler(s)
+	if m == nil {
+		return nil
 	}
+	// The server handles signals itself, programs would otherwise quit on
+	// SIGTERM before the shutdown broadcast reaches them.
+	opts = append(opts, tea.WithoutSignalHandler())
+	// Stop the program as soon as the session ends, along with the commands
+	// waiting on the session context.
+	opts = append(opts, tea.WithContext(s.Context()))
+	p := tea.NewProgram(m, append(opts, bubbletea.MakeOptions(s)...)...)
+	a.online.setProgram(sessionID(s), p)
+	return p
 }
 
-func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
-	// This should never fail, as we are using the activeterm middleware.
+func (a *app) teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
+	// Sessions without a PTY are answered by commandMiddleware before they
+	// get here.
 	pty, _, _ := s.Pty()
 
 	renderer := bubbletea.MakeRenderer(s)
-	mainStyle := renderer.NewStyle().MarginLeft(2)
-	checkboxStyle := renderer.NewStyle().Bold(false).Foreground(lipgloss.Color("213"))
-	aboutStyle := renderer.NewStyle().Bold(true).Foreground(lipgloss.Color("246"))
-	aboutNameStyle := renderer.NewStyle().Bold(true).Foreground(lipgloss.Color("15"))
-	subtleStyle := renderer.NewStyle().Foreground(lipgloss.Color("241"))
-	dotStyle := renderer.NewStyle().Foreground(lipgloss.Color("236")).Render(dotChar)
+	if on, message := a.maintenance.active(); on && !a.isAdmin(fingerprint(s)) {
+		setResult(s, resultMaintenance)
+		writeMaintenance(s, renderer, message)
+		return nil, nil
+	}
+	if a.cfg.HumanGate && !waitForKey(s, renderer, glyphsFor(pty.Term)) {
+		return nil, nil
+	}
+
+	var clipboard *termenv.Output
+	if supportsOSC52(pty.Term) {
+		clipboard = renderer.Output()
+	}
+
+	m := a.newModel(renderer, pty.Window.Width, pty.Window.Height, glyphsFor(pty.Term), a.prefs.get(fingerprint(s)))
+	m.clipboard = clipboard
+	m.sess = s
+	m.sessionID = sessionID(s)
+	m.summary.start = time.Now()
+	m.ctx = traceContext(s)
+	m.ip = remoteIP(s)
+	m.city = a.geo.city(m.ip)
+	m.location = visitorLocation(s)
+	m.tr = a.locales.forEnv(s.Environ())
+	m.tagline = a.taglines.pick()
+	m.fingerprint = fingerprint(s)
+	m.inline = a.cfg.NoAltScreen || !supportsAltScreen(pty.Term)
+	// The avatar is drawn at a fixed position of the screen, which the
+	// card only has in the alt screen.
+	if a.avatar != nil && !m.inline {
+		m.avatar = detectImageProtocol(pty.Term, s.Environ())
+		m.avatarPNG = a.avatar
+	}
+	m.hyperlinks = supportsHyperlinks(pty.Term, s.Environ())
+	if a.isAdmin(m.fingerprint) {
+		m.isAdmin = true
+		m, _ = m.showAdmin()
+	} else if tok, ok := a.views.take(m.fingerprint); ok {
+		m, m.restoreCmd = m.restoreView(tok)
+	}
+	// Clicks are reported at screen positions, which only match the card in
+	// the alt screen.
+	if m.plain || m.inline {
+		return m, nil
+	}
+	return m, []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+}
 
+// newModel builds the model for a width x height window rendered with
+// renderer and drawn with g, applying the saved preferences p. The fields tied to the SSH
+// session are left for the caller to fill in, so the model can be built
+// without one.
+func (a *app) newModel(renderer *lipgloss.Renderer, width, height int, g glyphs, p prefs) model {
 	m := model{
-		Width:          pty.Window.Width,
-		Height:         pty.Window.Height,
-		Choice:         0,
-		Chosen:         false,
-		mainStyle:      mainStyle,
-		aboutStyle:     aboutStyle,
-		aboutNameStyle: aboutNameStyle,
-		checkboxStyle:  checkboxStyle,
-		subtleStyle:    subtleStyle,
-		dotStyle:       dotStyle,
-		sess:           s,
-		runtime:        "",
-	}
-	return m, []tea.ProgramOption{tea.WithAltScreen()}
+		renderer:      renderer,
+		glyphs:        g,
+		visitors:      a.visitors,
+		book:          a.guestbook,
+		online:        a.online,
+		colorProfile:  renderer.ColorProfile(),
+		prefs:         a.prefs,
+		repos:         a.projects,
+		posts:         a.posts,
+		skills:        a.skills,
+		changelogText: a.changelog,
+		blogPages:     newPager(),
+		spotify:       a.spotify,
+		weatherClient: a.weather,
+		items:         a.items.get(),
+		location:      time.Local,
+		tr:            a.locales.english(),
+		history:       a.history,
+		ctx:           context.Background(),
+		typing:        a.cfg.Typewriter,
+		confirmQuit:   a.cfg.ConfirmQuit,
+		openURLs:      a.cfg.OpenURLs,
+		animateCaret:  !a.cfg.ReducedMotion,
+		borderName:    a.cfg.Border,
+		publicHost:    a.cfg.PublicHost,
+	}
+	if a.cfg.AskName {
+		m.state = stateName
+		m.nameInput = newNameInput()
+	}
+
+	theme := 0
+	if i := themeIndex(a.cfg.Theme); i >= 0 {
+		theme = i
+	} else if !renderer.HasDarkBackground() {
+		theme = max(themeIndex("light"), 0)
+	}
+	if i := themeIndex(p.Theme); i >= 0 {
+		theme = i
+	}
+	m = m.withTheme(theme)
+	if a.cfg.Plain {
+		m, _ = m.setPlain(true)
+	}
+	if label, _ := m.choiceLink(p.Choice); label != "" {
+		m.Choice = p.Choice
+	}
+
+	return m.resize(width, height)
 }
 
 const (
-	dotChar = " • "
+	// The smallest window the card can be rendered in.
+	minWidth  = 40
+	minHeight = 15
 
 	RESUME_URL   = "https://drive.google.com/file/d/1azKao3idMCDqJdCHtCTlvc4U3ABYTtJ7/view?usp=sharing"
 	GITHUB_URL   = "https://github.com/KaustubhPatange"
@@ -99,134 +519,639 @@ const (
 
 // Just a generic tea.Model to demo terminal information of ssh.
 type model struct {
+	// Width and Height are the room the views have, inside the border if
+	// there's one.
 	Width          int
 	Height         int
+	windowWidth    int
+	windowHeight   int
+	borderName     string // of SSH_BORDER
+	publicHost     string // of SSH_PUBLIC_HOST
 	Choice         int
 	Chosen         bool
+	tooSmall       bool
+	showHelp       bool
+	confirmQuit    bool
+	openURLs       bool // see openChoice
+	confirmingQuit bool
+	goodbye        string
+	ctx            context.Context // done when the session ends, and traced
+	isAdmin        bool
+	admin          adminModel
+	renderer       *lipgloss.Renderer
+	theme          int
 	mainStyle      lipgloss.Style
 	aboutStyle     lipgloss.Style
 	aboutNameStyle lipgloss.Style
 	checkboxStyle  lipgloss.Style
 	subtleStyle    lipgloss.Style
 	dotStyle       string
+	caretStyle     lipgloss.Style
+	linkStyle      lipgloss.Style
+	qrStyle        lipgloss.Style
+	errorStyle     lipgloss.Style
+	helpStyle      lipgloss.Style
+	itemStyles     []lipgloss.Style
+	items          []menuItem
+	banner         string
+	tagline        string
+	dimColor       lipgloss.TerminalColor
+	clipboard      *termenv.Output
+	visitors       *visitorCounter
+	book           *guestbook
+	online         *sessionRegistry
 	sess           ssh.Session
-	runtime        string
+	sessionID      string
+	fingerprint    string
+	prefs          *prefStore
+	repos          *projectCache
+	projects       *projectsMsg
+	posts          []post
+	skills         []skill
+	changelogText  string // markdown shown in the changelog view
+	blogPages      pager
+	filter         listFilter
+	projectPages   pager
+	spinner        spinner.Model
+	spotify        *spotifyClient
+	playing        *track
+	weatherClient  *weatherClient
+	weather        *weather
+	nameInput      textinput.Model
+	visitorName    string
+	post           viewport.Model
+	colorProfile   termenv.Profile
+	ip             string
+	city           string
+	avatar         imageProtocol
+	avatarPNG      []byte
+	hyperlinks     bool
+	animateCaret   bool
+	plain          bool
+	inline         bool // rendered below the prompt, without the alt screen
+	caretFrame     int
+	location       *time.Location
+	tr             translation
+	glyphs         glyphs
+	// restoreCmd is run by Init, for what the view restored from a previous
+	// session needs.
+	restoreCmd tea.Cmd
+	history    *connectionHistory
+	typing     bool
+	pendingG   bool
+	typed      int
+	state      viewState
+	errorText  string
+	// skillsFrame is how far the skill bars of the animation skillsAnim
+	// filled.
+	skillsAnim  int
+	skillsFrame int
+	qr          string
+	guestbook   guestbookModel
+	snake       snakeModel
+	konami      int
+	resume      viewport.Model
+	changelog   viewport.Model
+	menu        viewport.Model
+	status      string
+	statusID    int
+	idleWarning int // the statusID of the idle warning, 0 when not shown
+	summary     sessionSummary
 }
 
+// shutdownMsg tells the program that the server is shutting down.
+type shutdownMsg struct{}
+
+type viewState int
+
+const (
+	stateMenu viewState = iota
+	stateLink
+	stateQR
+	stateGuestbook
+	stateOnline
+	stateResume
+	stateProjects
+	stateContact
+	stateSnake
+	stateName
+	stateAdmin
+	stateBlog
+	statePost
+	stateStats
+	stateError
+	stateSkills
+	stateShare
+	stateChangelog
+)
+
 func (m model) Init() tea.Cmd {
-	return nil
+	var cmds []tea.Cmd
+	// The about text is typed once the visitor gave their name.
+	switch {
+	case m.state == stateAdmin:
+		cmds = append(cmds, onlineTick())
+	case m.state == stateName:
+		cmds = append(cmds, textinput.Blink)
+	case m.typing:
+		cmds = append(cmds, typewriterTick())
+	}
+	if m.spotify != nil {
+		cmds = append(cmds, loadNowPlaying(m.ctx, m.spotify))
+	}
+	if m.weatherClient != nil {
+		cmds = append(cmds, loadWeather(m.ctx, m.weatherClient))
+	}
+	if m.animateCaret {
+		cmds = append(cmds, caretTick())
+	}
+	cmds = append(cmds, m.restoreCmd, historyTick(), m.redrawAvatar(avatarFrame{}))
+	return tea.Batch(cmds...)
 }
 
-type openNextRuntime struct{}
-
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	before := m.avatarFrame()
+	next, cmd := m.update(msg)
+	next.summary = next.summary.track(msg, next)
+	return next, tea.Batch(cmd, next.redrawAvatar(before))
+}
+
+func (m model) update(msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.Width = msg.Width
-		m.Height = msg.Height
+		m = m.resize(msg.Width, msg.Height)
+	case onlineTickMsg:
+		switch m.state {
+		case stateOnline, stateStats:
+			return m, onlineTick()
+		case stateAdmin:
+			m.admin = m.admin.refresh()
+			return m, onlineTick()
+		}
+	case tea.MouseMsg:
+		m = m.clearIdleWarning()
+		if m.confirmingQuit {
+			return m, nil
+		}
+		return m.updateMouse(msg)
+	case typewriterTickMsg:
+		return m.updateTypewriter()
+	case caretTickMsg:
+		m.caretFrame++
+		return m, caretTick()
+	case historyTickMsg:
+		return m, historyTick()
+	case shutdownMsg:
+		// Leave the alt screen first so the goodbye stays on the terminal.
+		m.goodbye = "Server is going down for maintenance, goodbye!"
+		return m, tea.Sequence(tea.ExitAltScreen, tea.Quit)
+	case kickedMsg:
+		m.goodbye = "You've been disconnected, goodbye!"
+		return m, tea.Sequence(tea.ExitAltScreen, tea.Quit)
+	case avatarMsg:
+		if msg.frame == m.avatarFrame() {
+			return m, m.drawAvatar()
+		}
+	case idleWarningMsg:
+		return m.warnIdle(msg.lead)
+	case adminMsg:
+		return m.setStatusFor(m.glyphs.mail+" "+msg.text, messageTimeout)
+	case spinner.TickMsg:
+		// The spinner stops once what it's waiting for arrives.
+		if m.loading() {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	case nowPlayingMsg:
+		m.playing = msg.track
+		return m, nowPlayingTick(m.ctx, m.spotify)
+	case weatherMsg:
+		m.weather = msg.weather
+		return m, weatherTick(m.ctx, m.weatherClient)
+	case projectsMsg:
+		m.projects = &msg
+		m = m.paginateProjects()
+	case errorMsg:
+		m = m.showError(msg)
+	case skillsTickMsg:
+		return m.updateSkillsTick(msg)
+	case clearStatusMsg:
+		if msg.id == m.statusID {
+			m.status = ""
+		}
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
+		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
+		}
+		m = m.clearIdleWarning()
+		if m.confirmingQuit {
+			return m.updateQuit(msg)
+		}
+		if m.state == stateName {
+			return m.updateName(msg)
+		}
+		if m.state == stateAdmin {
+			return m.updateAdmin(msg)
+		}
+		// Any key skips the animation.
+		if m.typing {
+			m.typing = false
+			return m, nil
+		}
+		if m.state == stateGuestbook {
+			if msg.String() == "esc" {
+				m.state = stateMenu
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.guestbook, cmd = m.guestbook.Update(msg)
+			return m, cmd
+		}
+		if m.state == stateSnake {
+			return m.updateSnake(msg)
+		}
+		switch msg.String() {
+		case "q":
+			return m.quit()
+		case "?":
+			m.showHelp = !m.showHelp
+			return m, nil
+		case "t":
+			m = m.withTheme((m.theme + 1) % len(themes))
+			name := themes[m.theme].name
+			m.prefs.update(m.fingerprint, func(p *prefs) { p.Theme = name })
+			m = m.resize(m.windowWidth, m.windowHeight)
+			return m.setStatus("Theme: " + name)
+		case "P":
+			return m.setPlain(!m.plain)
+		case "esc":
+			if m.showHelp {
+				m.showHelp = false
+				return m, nil
+			}
+		}
+		if m.showHelp {
+			return m, nil
+		}
+		if m.state == stateBlog || m.state == statePost {
+			return m.updateBlog(msg)
+		}
+		if m.state == stateProjects {
+			return m.updateProjects(msg)
+		}
+		if m.state == stateError {
+			return m.updateError(msg)
+		}
+		if m.state == stateSkills {
+			return m.updateSkills(msg)
+		}
+		if m.state == stateShare {
+			return m.updateShare(msg)
+		}
+		if m.state == stateChangelog {
+			return m.updateChangelog(msg)
+		}
+		if m.state != stateMenu {
+			switch msg.String() {
+			case "esc", "backspace", "h", "left":
+				m.state = stateMenu
+			case "r":
+				m = m.showQR()
+			case "c":
+				return m.copyChoice()
+			case "o":
+				if m.state == stateResume {
+					m.state = stateLink
+				}
+			default:
+				if m.state == stateResume {
+					var cmd tea.Cmd
+					m.resume, cmd = m.resume.Update(msg)
+					return m, cmd
+				}
+			}
+			return m, nil
+		}
+		if m.konami = konamiProgress(m.konami, msg.String()); m.konami == len(konamiCode) {
+			m.konami = 0
+			return m.startSnake()
+		}
+		// "gg" jumps to the first choice, any other key cancels the pending g.
+		pendingG := m.pendingG
+		m.pendingG = false
+		switch msg.String() {
+		case "g":
+			if !pendingG {
+				m.pendingG = true
+				break
+			}
+			m.Choice = 0
+			m = m.scrollMenu()
+		case "G":
+			m.Choice = max(m.lastChoice(), 0)
+			m = m.scrollMenu()
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			if i := int(msg.Runes[0] - '1'); i <= m.lastChoice() {
+				m.Choice = i
+				m = m.scrollMenu()
+			}
 		case "j", "down":
-			m.Choice++
-			if m.Choice > 3 {
-				m.Choice = 3
+			// Past the last choice keep scrolling the rest of the menu.
+			if m.Choice >= m.lastChoice() {
+				m.menu.LineDown(1)
+				break
 			}
+			m.Choice++
+			m = m.scrollMenu()
 		case "k", "up":
-			m.Choice--
-			if m.Choice < 0 {
-				m.Choice = 0
+			// Above the first choice keep scrolling up to the about text.
+			if m.Choice <= 0 {
+				m.menu.LineUp(1)
+				break
 			}
+			m.Choice--
+			m = m.scrollMenu()
+		case "pgdown", "ctrl+d":
+			m.menu.HalfViewDown()
+		case "pgup", "ctrl+u":
+			m.menu.HalfViewUp()
 		case "enter":
-			m.runtime = "linux"
-			return openByChoice(m)
+			return m.openChoice()
+		case "r":
+			m.rememberChoice()
+			m = m.showQR()
+		case "c":
+			m.rememberChoice()
+			return m.copyChoice()
+		case "C":
+			return m.copyAll()
+		case "m":
+			name := m.sess.User()
+			if m.visitorName != "" {
+				name = m.visitorName
+			}
+			m.guestbook = newGuestbookModel(m.book, m.ip, name, m.aboutNameStyle, m.aboutStyle, m.subtleStyle).withHeight(m.bodyHeight() - 8)
+			m.state = stateGuestbook
+			return m, m.guestbook.Init()
+		case "w":
+			m.state = stateOnline
+			return m, onlineTick()
+		case "S":
+			m.state = stateStats
+			return m, onlineTick()
+		case "p":
+			return m.showProjects()
+		case "i":
+			return m.showShare(), nil
+		case "s":
+			return m.startSnake()
+		case "a":
+			if m.isAdmin {
+				return m.showAdmin()
+			}
 		}
-	case openNextRuntime:
-		switch m.runtime {
-		case "linux":
-			m.runtime = "darwin"
-			return openByChoice(m)
-		case "darwin":
-			m.runtime = "windows"
-			return openByChoice(m)
-		default:
-			m.runtime = ""
+	case snakeTickMsg:
+		if m.state == stateSnake {
+			return m.updateSnake(msg)
+		}
+	default:
+		if m.state == stateName {
+			return m.updateName(msg)
+		}
+		if m.state == stateAdmin {
+			return m.updateAdmin(msg)
+		}
+		if m.state == stateGuestbook {
+			var cmd tea.Cmd
+			m.guestbook, cmd = m.guestbook.Update(msg)
+			return m, cmd
 		}
 	}
 	return m, nil
 }
 
 func (m model) View() string {
+	if m.goodbye != "" {
+		return m.aboutStyle.Render(m.goodbye) + "\n"
+	}
+	if m.plain {
+		if m.state == stateMenu && !m.showHelp && !m.confirmingQuit {
+			return m.plainView()
+		}
+		return m.content()
+	}
+	if m.tooSmall {
+		msg := m.aboutStyle.Copy().Align(lipgloss.Center).Render(fmt.Sprintf("Please enlarge your terminal\n(min %dx%d)", minWidth, minHeight))
+		return lipgloss.Place(m.Width, m.Height, lipgloss.Center, lipgloss.Center, msg)
+	}
+	return m.withBorder(m.withFooter(m.content()))
+}
 
-	about := m.aboutStyle.Render(fmt.Sprintf(strings.TrimSpace(`
-Hi I'm %s,
+// content renders the current view.
+func (m model) content() string {
+	if m.confirmingQuit {
+		return m.quitView()
+	}
+	if m.showHelp {
+		return m.helpView()
+	}
 
-A self taught developer specialized in many software domains
-including Mobile Apps, Web, Backend, Gen AI.
+	switch m.state {
+	case stateLink:
+		return m.linkView()
+	case stateQR:
+		return m.qrView()
+	case stateGuestbook:
+		return m.guestbookView()
+	case stateOnline:
+		return m.onlineView()
+	case stateStats:
+		return m.statsView()
+	case stateProjects:
+		return m.projectsView()
+	case stateContact:
+		return m.contactView()
+	case stateSnake:
+		return m.snakeView()
+	case stateName:
+		return m.nameView()
+	case stateAdmin:
+		return m.adminView()
+	case stateBlog:
+		return m.blogView()
+	case statePost:
+		return m.postView()
+	case stateResume:
+		return m.resumeView()
+	case stateError:
+		return m.errorView()
+	case stateSkills:
+		return m.skillsView()
+	case stateShare:
+		return m.shareView()
+	case stateChangelog:
+		return m.changelogView()
+	}
 
-I'm currently working at an AI startup as a FullStack 
-Engineer.
+	body, _ := m.menuBody()
+	footer := m.menuFooter()
+	if !m.menuOverflows(body) {
+		s := fmt.Sprintf("%s\n\n%s", body, footer)
+		return m.mainStyle.Render("\n" + s + "\n\n")
+	}
 
-I'm fluent in Python, Go, Typescript, Javascript, Kotlin.
-`), m.aboutNameStyle.Render("Kaustubh Patange")))
+	vp := m.menu
+	vp.SetContent(body)
+	indicator := m.subtleStyle.Render(fmt.Sprintf("%s %3.f%%  ", m.glyphs.scroll, vp.ScrollPercent()*100))
+	return m.mainStyle.Render("\n" + vp.View() + "\n\n" + indicator + footer)
+}
 
-	tpl := m.subtleStyle.Render("Hint: q, ctrl+c: quit")
+const myName = "Kaustubh Patange"
 
-	choices := fmt.Sprintf(
-		"%s\n%s\n%s\n%s",
-		m.subtleStyle.Copy().Foreground(lipgloss.Color("222")).Render("Resume / CV    https://kaustubhpatange.com/resume"),
-		m.subtleStyle.Copy().Foreground(lipgloss.Color("13")).Render("GitHub         https://github.com/KaustubhPatange"),
-		m.subtleStyle.Copy().Foreground(lipgloss.Color("33")).Render("Linkedin       https://linkedin.com/in/kaustubhpatange"),
-		m.subtleStyle.Copy().Foreground(lipgloss.Color("39")).Render("Twitter        https://twitter.com/KP206"),
-	)
+// menuBody renders the about text followed by the menu, along with the line
+// the current choice is rendered on.
+func (m model) menuBody() (string, int) {
+	about := m.about()
 
-	s := fmt.Sprintf("%s\n\n%s\n\n%s", about, choices, tpl)
-	return m.mainStyle.Render("\n" + s + "\n\n")
+	lines := make([]string, len(m.items))
+	for i, item := range m.items {
+		style := m.itemStyles[i%len(m.itemStyles)]
+		label := m.linkLine(style.Render(padRight(m.tr.label(item.label), 14)+" "+item.display), item.url, m.Width-8)
+		lines[i] = m.checkbox(label, m.Choice == i)
+		if m.Choice == i {
+			lines[i] += m.caret()
+		}
+	}
+	choices := strings.Join(lines, "\n")
+
+	body, offset := m.withBanner(fmt.Sprintf("%s\n\n%s", about, choices))
+	return body, offset + lipgloss.Height(about) + 1 + m.Choice
 }
 
-func checkbox(checkboxStyle lipgloss.Style, label string, checked bool) string {
-	if checked {
-		return checkboxStyle.Render("[x] " + label)
+// openChoice runs the action of the current choice, showing the link view
+// for items without one.
+//
+// Links are shown to the visitor, through the link view, QR code, OSC 8
+// hyperlink or clipboard. Only with SSH_OPEN_URLS are they also opened on
+// the server, which is meant for running the card on your own machine: the
+// browser is launched on the host, not on the visitor's machine, so on a
+// public server anyone connecting could spawn processes on it as often as
+// they press enter, and would see nothing open anyway.
+func (m model) openChoice() (model, tea.Cmd) {
+	if m.Choice < 0 || m.Choice > m.lastChoice() {
+		return m, nil
 	}
-	return fmt.Sprintf("[ ] %s", label)
+	m.rememberChoice()
+	item := m.items[m.Choice]
+	m.traceEvent("menu.open", attribute.String("menu.item", item.label))
+	var browse tea.Cmd
+	if m.openURLs && isWebURL(item.url) {
+		browse = openURL(item.url)
+	}
+	if item.open != nil {
+		var cmd tea.Cmd
+		m, cmd = item.open(m)
+		return m, tea.Batch(cmd, browse)
+	}
+	m.state = stateLink
+	return m, browse
 }
 
-func openByChoice(m model) (tea.Model, tea.Cmd) {
-	switch m.Choice {
-	case 0:
-		return m, openURL(m, RESUME_URL)
-	case 1:
-		return m, openURL(m, GITHUB_URL)
-	case 2:
-		return m, openURL(m, LINKEDIN_URL)
-	case 3:
-		return m, openURL(m, TWITTER_URL)
-	}
-	return m, nil
+// rememberChoice saves the current choice, to preselect it the next time the
+// visitor connects.
+func (m model) rememberChoice() {
+	choice := m.Choice
+	m.prefs.update(m.fingerprint, func(p *prefs) { p.Choice = choice })
 }
 
-func openURL(m model, url string) tea.Cmd {
-	var cmd string
-	var args []string
+func (m model) menuFooter() string {
+	visitors := fmt.Sprintf("visitors: %d", m.visitors.count())
+	return m.hintLine(visitors)
+}
 
-	runtime := m.runtime
+// menuOverflows reports whether the menu body plus its footer is taller than
+// the window, in which case it's rendered into a scrollable viewport.
+func (m model) menuOverflows(body string) bool {
+	return lipgloss.Height(body)+4 > m.bodyHeight()
+}
 
-	switch runtime {
-	case "linux":
-		cmd = "xdg-open"
-	case "darwin":
-		cmd = "open"
-	default:
-		cmd = "cmd"
-		args = []string{"/c", "start"}
+// scrollMenu resizes the menu viewport to the window and scrolls it so the
+// current choice is visible.
+func (m model) scrollMenu() model {
+	body, line := m.menuBody()
+	m.menu.Width = max(m.Width-2, 1)
+	m.menu.Height = max(m.bodyHeight()-4, 1)
+	m.menu.SetContent(body)
+	if line < m.menu.YOffset {
+		m.menu.SetYOffset(line)
+	} else if line >= m.menu.YOffset+m.menu.Height {
+		m.menu.SetYOffset(line - m.menu.Height + 1)
+	}
+	return m
+}
+
+// hint renders the key bindings of a view as a subtle, dot separated line,
+// followed by the current status if any, on the next line if it doesn't fit.
+func (m model) hint(keys ...string) string {
+	for i, k := range keys {
+		keys[i] = m.subtleStyle.Render(k)
+	}
+	s := m.subtleStyle.Render("Hint: ") + strings.Join(keys, m.dotStyle)
+	if m.status == "" {
+		return s
+	}
+	status := m.checkboxStyle.Render(m.status)
+	if lipgloss.Width(s)+2+lipgloss.Width(status) > m.Width-2 {
+		return s + "\n" + status
+	}
+	return s + "  " + status
+}
+
+func (m model) checkbox(label string, checked bool) string {
+	if checked {
+		return m.checkboxStyle.Render(m.glyphs.checked + " " + label)
 	}
-	args = append(args, url)
-	c := wish.Command(m.sess, cmd, args...)
+	return m.glyphs.unchecked + " " + label
+}
 
-	cmdExec := tea.Exec(c, func(_ error) tea.Msg {
+// linkView shows the chosen link to the visitor. The raw URL is always
+// printed, followed by an OSC 8 hyperlink which is clickable in terminals that
+// support it.
+func (m model) linkView() string {
+	label, url := m.choiceLink(m.Choice)
 
-		return openNextRuntime{}
-	})
-	return cmdExec
+	title := m.aboutNameStyle.Render(label)
+	link := m.linkStyle.Render(url)
+	open := m.hyperlink(url, m.aboutStyle.Render("Open in browser "+m.glyphs.external))
+	hint := m.subtleStyle.Render("Copy the link above, or ctrl/cmd + click it if your terminal supports it.")
+	tpl := m.hintLine()
+
+	s := fmt.Sprintf("%s\n\n%s\n%s\n\n%s\n\n%s", title, link, open, hint, tpl)
+	return m.mainStyle.Render("\n" + s + "\n\n")
+}
+
+// qrView shows the QR code rendered by showQR, so it can be scanned with a
+// phone instead of being typed in.
+func (m model) qrView() string {
+	label, _ := m.choiceLink(m.Choice)
+
+	title := m.aboutNameStyle.Render(label)
+	tpl := m.hintLine()
+
+	s := fmt.Sprintf("%s\n\n%s\n\n%s", title, m.qr, tpl)
+	return m.mainStyle.Render("\n" + s + "\n")
+}
+
+// showQR renders the QR code of the current choice sized to the window and
+// switches to the QR view. The rendered code is kept on the model so View
+// doesn't have to regenerate it.
+func (m model) showQR() model {
+	_, url := m.choiceLink(m.Choice)
+	if url == "" {
+		return m
+	}
+	m.qr = m.qrStyle.Render(renderQR(url, m.Width-2, m.bodyHeight()-6))
+	m.state = stateQR
+	return m
 }
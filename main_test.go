@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/exp/golden"
+	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/muesli/termenv"
+)
+
+// testSkills and testChangelog are the languages and changelog of the test
+// card.
+var (
+	testSkills    = []skill{{"Go", 0.9}, {"Kotlin", 0.8}}
+	testChangelog = "## 2024-01-02\n\n- Something new.\n"
+)
+
+// newTestModel builds the model of a width x height session without colors,
+// with the state files in a temporary directory. It's always 8am where the
+// model is, so it's greeted the same way whenever the tests run.
+func newTestModel(t *testing.T, width, height int) model {
+	t.Helper()
+	dir := t.TempDir()
+	visitors, err := loadVisitorCounter(filepath.Join(dir, "visitors.count"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	book, err := loadGuestbook(filepath.Join(dir, "guestbook.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefs, err := loadPrefStore(filepath.Join(dir, "prefs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	locales, err := loadLocales(localesFS, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &app{
+		visitors:  visitors,
+		guestbook: book,
+		online:    newSessionRegistry(),
+		prefs:     prefs,
+		skills:    testSkills,
+		changelog: testChangelog,
+		items:     &itemStore{items: newMenuItems("me@example.com", nil, testSkills, testChangelog, defaultLinks)},
+		locales:   locales,
+		history:   &connectionHistory{},
+	}
+	renderer := lipgloss.NewRenderer(io.Discard, termenv.WithProfile(termenv.Ascii))
+	m := a.newModel(renderer, width, height, glyphsFor("xterm-256color"), prefs.get(""))
+	m.location = time.FixedZone("test", (8-time.Now().UTC().Hour())*60*60)
+	return m
+}
+
+func keyMsg(k string) tea.KeyMsg {
+	switch k {
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "ctrl+c":
+		return tea.KeyMsg{Type: tea.KeyCtrlC}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+// press sends the keys to m in turn, returning the model and the command of
+// the last one.
+func press(m model, keys ...string) (model, tea.Cmd) {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		var next tea.Model
+		next, cmd = m.Update(keyMsg(k))
+		m = next.(model)
+	}
+	return m, cmd
+}
+
+// quits reports whether cmd quits the program, on its own or in a batch.
+func quits(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	switch msg := cmd().(type) {
+	case tea.QuitMsg:
+		return true
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			if quits(cmd) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestUpdateNavigation(t *testing.T) {
+	n := len(newTestModel(t, 80, 24).items)
+	last := n - 1
+	tests := []struct {
+		name  string
+		items int
+		start int
+		keys  []string
+		want  int
+	}{
+		{"down", n, 0, []string{"down"}, 1},
+		{"j", n, 0, []string{"j", "j"}, 2},
+		{"up", n, 2, []string{"up"}, 1},
+		{"k", n, 2, []string{"k"}, 1},
+		{"up past the first", n, 0, []string{"up", "k"}, 0},
+		{"down past the last", n, last - 1, []string{"down", "down", "j"}, last},
+		{"G", n, 0, []string{"G"}, last},
+		{"gg", n, last, []string{"g", "g"}, 0},
+		{"number", n, 0, []string{"3"}, 2},
+		{"number past the last", n, 0, []string{"9"}, 0},
+		{"up with one item", 1, 0, []string{"up", "k"}, 0},
+		{"down with one item", 1, 0, []string{"down", "j"}, 0},
+		{"G with one item", 1, 0, []string{"G"}, 0},
+		{"number with one item", 1, 0, []string{"2"}, 0},
+		{"up with no items", 0, 0, []string{"up", "k"}, 0},
+		{"down with no items", 0, 0, []string{"down", "j"}, 0},
+		{"G with no items", 0, 0, []string{"G"}, 0},
+		{"gg with no items", 0, 0, []string{"g", "g"}, 0},
+		{"number with no items", 0, 0, []string{"1"}, 0},
+		{"enter with no items", 0, 0, []string{"enter"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, 80, 24)
+			m.items = m.items[:tt.items]
+			m.Choice = tt.start
+			m, cmd := press(m, tt.keys...)
+			if m.Choice != tt.want {
+				t.Errorf("choice = %d, want %d", m.Choice, tt.want)
+			}
+			if m.state != stateMenu {
+				t.Errorf("state = %d, want the menu", m.state)
+			}
+			if quits(cmd) {
+				t.Error("navigating quit")
+			}
+			_ = m.View()
+		})
+	}
+}
+
+func TestUpdateQuit(t *testing.T) {
+	tests := []struct {
+		name        string
+		confirmQuit bool
+		keys        []string
+		want        bool
+	}{
+		{"q", false, []string{"q"}, true},
+		{"ctrl+c", false, []string{"ctrl+c"}, true},
+		{"q asks first", true, []string{"q"}, false},
+		{"q confirmed", true, []string{"q", "y"}, true},
+		{"q cancelled", true, []string{"q", "n"}, false},
+		{"ctrl+c doesn't ask", true, []string{"ctrl+c"}, true},
+		{"other key", false, []string{"x"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, 80, 24)
+			m.confirmQuit = tt.confirmQuit
+			if _, cmd := press(m, tt.keys...); quits(cmd) != tt.want {
+				t.Errorf("quit = %t, want %t", !tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateEnter(t *testing.T) {
+	m := newTestModel(t, 80, 24)
+	want := map[string]viewState{
+		"Resume / CV": stateResume,
+		"Blog":        stateBlog,
+		"Skills":      stateSkills,
+		"Changelog":   stateChangelog,
+		"GitHub":      stateLink,
+		"Linkedin":    stateLink,
+		"Twitter":     stateLink,
+		"Contact":     stateContact,
+	}
+	for i, item := range m.items {
+		t.Run(item.label, func(t *testing.T) {
+			state, ok := want[item.label]
+			if !ok {
+				t.Fatalf("no state for %s", item.label)
+			}
+			m := newTestModel(t, 80, 24)
+			m.Choice = i
+			m, _ = press(m, "enter")
+			if m.state != state {
+				t.Errorf("state = %d, want %d", m.state, state)
+			}
+			if got, _ := press(m, "esc"); got.state != stateMenu {
+				t.Errorf("esc left state %d, want the menu", got.state)
+			}
+		})
+	}
+}
+
+func TestMenuWithoutContactEmail(t *testing.T) {
+	for _, item := range newMenuItems("", nil, nil, "", defaultLinks) {
+		if item.label == "Contact" {
+			t.Errorf("contact item %q without an address", item.display)
+		}
+	}
+}
+
+// TestViewGolden compares the card rendered at a few window sizes with the
+// golden files in testdata, which -update rewrites.
+func TestViewGolden(t *testing.T) {
+	// The footer shows the uptime.
+	defer func(start time.Time) { startTime = start }(startTime)
+	startTime = time.Now()
+
+	for _, size := range []struct{ width, height int }{{80, 24}, {120, 40}, {minWidth, minHeight}} {
+		t.Run(fmt.Sprintf("%dx%d", size.width, size.height), func(t *testing.T) {
+			golden.RequireEqual(t, []byte(newTestModel(t, size.width, size.height).View()))
+		})
+	}
+}
+
+// waitFor waits until the output of tm shows text.
+func waitFor(t *testing.T, tm *teatest.TestModel, text string) {
+	t.Helper()
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		return bytes.Contains(out, []byte(text))
+	}, teatest.WithDuration(5*time.Second))
+}
+
+// TestSessionCopyLinkFromQR goes through a whole session of a visitor who
+// last opened the changelog: they go down to LinkedIn, open it, show its QR
+// code, copy the link and quit.
+func TestSessionCopyLinkFromQR(t *testing.T) {
+	var clipboard bytes.Buffer
+	m := newTestModel(t, 80, 24)
+	m.clipboard = termenv.NewOutput(&clipboard)
+	m.Choice = 3
+	if m.items[m.Choice].label != "Changelog" {
+		t.Fatalf("choice %d is %s, not the changelog", m.Choice, m.items[m.Choice].label)
+	}
+	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(80, 24))
+
+	tm.Send(keyMsg("down"))
+	tm.Send(keyMsg("down"))
+	tm.Send(keyMsg("enter"))
+	waitFor(t, tm, LINKEDIN_URL)
+	tm.Send(keyMsg("r"))
+	waitFor(t, tm, "█")
+	tm.Send(keyMsg("c"))
+	waitFor(t, tm, "Copied!")
+	tm.Send(keyMsg("q"))
+
+	// The program only finishes once the model returned tea.Quit.
+	final := tm.FinalModel(t, teatest.WithFinalTimeout(5*time.Second)).(model)
+	if label := final.items[final.Choice].label; label != "Linkedin" {
+		t.Errorf("ended on %s, want Linkedin", label)
+	}
+	if final.state != stateQR {
+		t.Errorf("ended in state %d, want the QR code", final.state)
+	}
+	if final.status != "Copied!" {
+		t.Errorf("status = %q, want Copied!", final.status)
+	}
+	copied := base64.StdEncoding.EncodeToString([]byte(copyText(LINKEDIN_URL)))
+	if !strings.Contains(clipboard.String(), copied) {
+		t.Errorf("clipboard got %q, want %s", clipboard.String(), LINKEDIN_URL)
+	}
+	// waitFor read the output so far, so check the view the session ended on.
+	view := final.View()
+	for _, want := range []string{"Linkedin", "█", "Copied!"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("last view is missing %q:\n%s", want, view)
+		}
+	}
+}
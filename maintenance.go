@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+)
+
+const defaultMaintenanceMessage = "Back soon!"
+
+// maintenanceMode shows new sessions a message instead of the card and
+// disconnects them, while it's turned on in the config or its file exists.
+// Admins still get the card.
+type maintenanceMode struct {
+	mu      sync.RWMutex
+	on      bool
+	file    string
+	message string
+}
+
+func newMaintenanceMode(cfg Config) *maintenanceMode {
+	m := &maintenanceMode{}
+	m.set(cfg)
+	return m
+}
+
+// set applies the maintenance settings of cfg.
+func (m *maintenanceMode) set(cfg Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.on, m.file, m.message = cfg.Maintenance, cfg.MaintenanceFile, cfg.MaintenanceMessage
+}
+
+// active reports whether maintenance mode is on, along with the message to
+// show.
+func (m *maintenanceMode) active() (bool, string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.on {
+		return true, m.message
+	}
+	if m.file != "" {
+		if _, err := os.Stat(m.file); err == nil {
+			return true, m.message
+		}
+	}
+	return false, ""
+}
+
+// writeMaintenance tells the visitor of session s that the card is down for
+// maintenance.
+func writeMaintenance(s ssh.Session, renderer *lipgloss.Renderer, message string) {
+	style := renderer.NewStyle().Bold(true).Foreground(themes[0].accent).MarginLeft(2)
+	fmt.Fprintf(crlfWriter{s}, "\n%s\n\n", style.Render(message))
+}
+
+// reloadMaintenance applies the maintenance settings of cfg, disconnecting
+// the sessions already connected when it's turned on and asked to.
+func (r *reloader) reloadMaintenance(cfg Config) bool {
+	was, _ := r.maintenance.active()
+	r.maintenance.set(cfg)
+	r.cfg.Maintenance, r.cfg.MaintenanceFile, r.cfg.MaintenanceMessage, r.cfg.MaintenanceDrain = cfg.Maintenance, cfg.MaintenanceFile, cfg.MaintenanceMessage, cfg.MaintenanceDrain
+	on, _ := r.maintenance.active()
+	if on == was {
+		return false
+	}
+	log.Info("Maintenance mode", "on", on)
+	if on && cfg.MaintenanceDrain {
+		go r.online.broadcast(context.Background(), shutdownMsg{})
+	}
+	return true
+}
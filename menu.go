@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// menuItem is an entry of the menu, display is the short form of url shown
+// next to the label. Opening it runs open, or shows the link view if it's nil.
+// Items without a url only have a view, and can't be copied or shown as a QR
+// code.
+type menuItem struct {
+	label   string
+	display string
+	url     string
+	open    func(model) (model, tea.Cmd)
+}
+
+// link is a menu item only opening a URL, as listed in the links file.
+type link struct {
+	Label   string `json:"label"`
+	URL     string `json:"url"`
+	Display string `json:"display,omitempty"`
+}
+
+// defaultLinks are the links of the menu when there's no links file.
+var defaultLinks = []link{
+	{"GitHub", GITHUB_URL, "https://github.com/KaustubhPatange"},
+	{"Linkedin", LINKEDIN_URL, "https://linkedin.com/in/kaustubhpatange"},
+	{"Twitter", TWITTER_URL, "https://twitter.com/KP206"},
+}
+
+// loadLinks reads the links of the menu from the JSON file at path, or
+// returns the defaultLinks if path is empty.
+func loadLinks(path string) ([]link, error) {
+	if path == "" {
+		return defaultLinks, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read links: %w", err)
+	}
+	var links []link
+	if err := json.Unmarshal(data, &links); err != nil {
+		return nil, fmt.Errorf("parse links %s: %w", path, err)
+	}
+	for i, l := range links {
+		if l.Label == "" {
+			return nil, fmt.Errorf("parse links %s: link %d has no label", path, i+1)
+		}
+		if u, err := url.Parse(l.URL); err != nil || u.Scheme == "" {
+			return nil, fmt.Errorf("parse links %s: invalid url %q for %s", path, l.URL, l.Label)
+		}
+		if l.Display == "" {
+			links[i].Display = l.URL
+		}
+	}
+	if len(links) == 0 {
+		return nil, fmt.Errorf("parse links %s: no links", path)
+	}
+	return links, nil
+}
+
+// newMenuItems returns the entries of the menu, with email as the contact
+// address if there's one.
+func newMenuItems(email string, posts []post, skills []skill, changelog string, links []link) []menuItem {
+	items := []menuItem{
+		{"Resume / CV", "https://kaustubhpatange.com/resume", RESUME_URL, withoutCmd(model.showResume)},
+		{"Blog", fmt.Sprintf("%d posts", len(posts)), "", withoutCmd(model.showBlog)},
+	}
+	if len(skills) > 0 {
+		items = append(items, menuItem{"Skills", fmt.Sprintf("%d languages", len(skills)), "", model.showSkills})
+	}
+	if strings.TrimSpace(changelog) != "" {
+		items = append(items, menuItem{"Changelog", updatedText(changelog), "", withoutCmd(model.showChangelog)})
+	}
+	for _, l := range links {
+		items = append(items, menuItem{l.Label, l.Display, l.URL, nil})
+	}
+	if email != "" {
+		items = append(items, menuItem{"Contact", email, "mailto:" + email, withoutCmd(model.showContact)})
+	}
+	return items
+}
+
+// withoutCmd adapts a view opening without a command to a menu item.
+func withoutCmd(show func(model) model) func(model) (model, tea.Cmd) {
+	return func(m model) (model, tea.Cmd) { return show(m), nil }
+}
+
+// itemStore holds the menu items, which are replaced when the links are
+// reloaded. Sessions keep the items they started with.
+type itemStore struct {
+	mu    sync.RWMutex
+	items []menuItem
+}
+
+func (s *itemStore) get() []menuItem {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.items
+}
+
+func (s *itemStore) set(items []menuItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = items
+}
+
+// lastChoice is the index of the last menu item.
+func (m model) lastChoice() int {
+	return len(m.items) - 1
+}
+
+// choiceLink returns the translated label and URL of the menu item at
+// choice, or empty strings if it's out of range.
+func (m model) choiceLink(choice int) (string, string) {
+	if choice < 0 || choice > m.lastChoice() {
+		return "", ""
+	}
+	return m.tr.label(m.items[choice].label), m.items[choice].url
+}
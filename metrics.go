@@ -0,0 +1,42 @@
+package main
+
+import (
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	connectionsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "ssh_connections_total",
+		Help: "Total number of SSH sessions.",
+	})
+	sessionsActive = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ssh_sessions_active",
+		Help: "Number of SSH sessions currently connected.",
+	})
+	sessionDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "ssh_session_duration_seconds",
+		Help:    "Duration of SSH sessions.",
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800},
+	})
+)
+
+// metricsMiddleware records every session in the Prometheus metrics.
+func metricsMiddleware() wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			start := time.Now()
+			connectionsTotal.Inc()
+			sessionsActive.Inc()
+			defer func() {
+				sessionsActive.Dec()
+				sessionDuration.Observe(time.Since(start).Seconds())
+			}()
+			next(s)
+		}
+	}
+}
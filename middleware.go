@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/muesli/termenv"
+)
+
+// idleSession resets the idle timers whenever the client sends input.
+type idleSession struct {
+	ssh.Session
+	timer   *time.Timer
+	timeout time.Duration
+	// warning is nil when the visitor isn't warned before the timeout.
+	warning *time.Timer
+	lead    time.Duration
+}
+
+func (s *idleSession) Read(p []byte) (int, error) {
+	n, err := s.Session.Read(p)
+	if n > 0 {
+		s.timer.Reset(s.timeout)
+		if s.warning != nil {
+			s.warning.Reset(s.timeout - s.lead)
+		}
+	}
+	return n, err
+}
+
+// idleWarningMsg tells the program the session is about to be closed for
+// inactivity, in lead.
+type idleWarningMsg struct{ lead time.Duration }
+
+// idleTimeoutMiddleware closes sessions which didn't send any input for the
+// given timeout. Their program in r is warned lead before, unless lead is 0.
+func idleTimeoutMiddleware(timeout, lead time.Duration, r *sessionRegistry) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			idle := &idleSession{Session: s, timeout: timeout, lead: lead}
+			idle.timer = time.AfterFunc(timeout, func() {
+				setResult(s, resultIdle)
+				disconnect(s, "Disconnected due to inactivity.")
+			})
+			defer idle.timer.Stop()
+			if lead > 0 {
+				idle.warning = time.AfterFunc(timeout-lead, func() {
+					r.send(sessionID(s), idleWarningMsg{lead})
+				})
+				defer idle.warning.Stop()
+			}
+			next(idle)
+		}
+	}
+}
+
+// maxDurationMiddleware closes sessions once they've been connected for
+// longer than limit, however active they are. It does nothing if limit is 0.
+func maxDurationMiddleware(limit time.Duration) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			if limit > 0 {
+				timer := time.AfterFunc(limit, func() {
+					setResult(s, resultMaxDuration)
+					disconnect(s, "You've been here a while, thanks for stopping by!")
+				})
+				defer timer.Stop()
+			}
+			next(s)
+		}
+	}
+}
+
+// sessionCount is the number of sessions admitted by maxSessionsMiddleware.
+var sessionCount atomic.Int64
+
+// activeSessions returns the number of currently connected sessions.
+func activeSessions() int64 {
+	return sessionCount.Load()
+}
+
+// maxSessionsMiddleware rejects new sessions once limit sessions are
+// connected.
+func maxSessionsMiddleware(limit int) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			for {
+				n := sessionCount.Load()
+				if n >= int64(limit) {
+					setResult(s, resultCapacity)
+					wish.Fatalln(s, "Server is at capacity, try again shortly.")
+					return
+				}
+				if sessionCount.CompareAndSwap(n, n+1) {
+					break
+				}
+			}
+			defer sessionCount.Add(-1)
+			next(s)
+		}
+	}
+}
+
+// maxSessionsPerIPMiddleware rejects new sessions from IPs which already
+// have limit sessions connected, so a single client can't take up all of
+// maxSessionsMiddleware's. Behind a proxy, the IP is the one of the PROXY
+// protocol header.
+func maxSessionsPerIPMiddleware(limit int) wish.Middleware {
+	var (
+		mu       sync.Mutex
+		sessions = make(map[string]int)
+	)
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			ip := remoteIP(s)
+			mu.Lock()
+			if sessions[ip] >= limit {
+				mu.Unlock()
+				log.Warn("Too many sessions from IP", "remote", ip, "limit", limit)
+				setResult(s, resultIPCapacity)
+				wish.Fatalln(s, "Too many sessions from your address.")
+				return
+			}
+			sessions[ip]++
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				defer mu.Unlock()
+				if sessions[ip]--; sessions[ip] == 0 {
+					delete(sessions, ip)
+				}
+			}()
+			next(s)
+		}
+	}
+}
+
+// logMiddleware logs every session when it connects and disconnects, with
+// structured fields so the logs are easy to query in JSON. The sessions of
+// every IP are sampled by sampler if it's not nil. With rdns, the hostname of
+// the IP is resolved for the connection line, which is then logged in the
+// background not to hold up the session.
+func logMiddleware(sampler *logSampler, rdns *reverseDNS) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			ip := remoteIP(s)
+			logged, suppressed := sampler.sample(ip)
+			if !logged {
+				next(s)
+				return
+			}
+			start := time.Now()
+			pty, _, isPty := s.Pty()
+			fields := []any{
+				"user", s.User(),
+				"ip", ip,
+			}
+			// Clients without keys authenticate with keyboard-interactive,
+			// they can't be told apart across sessions.
+			if pk := s.PublicKey(); pk != nil {
+				fields = append(fields, "key", pk.Type(), "fingerprint", fingerprint(s))
+			} else {
+				fields = append(fields, "key", "none")
+			}
+			// Clipped so the lines below don't share what's appended to it.
+			fields = slices.Clip(fields)
+			connected := append(fields,
+				"command", s.Command(),
+				"pty", isPty,
+				"term", pty.Term,
+				"width", pty.Window.Width,
+				"height", pty.Window.Height,
+				"client", s.Context().ClientVersion(),
+			)
+			if suppressed > 0 {
+				connected = append(connected, "suppressed", suppressed)
+			}
+			if rdns == nil {
+				log.Info("Session connected", connected...)
+				next(s)
+				log.Info("Session disconnected", append(fields, "duration", time.Since(start))...)
+				return
+			}
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				log.Info("Session connected", append(connected, "host", rdns.lookup(ip))...)
+			}()
+			next(s)
+			duration := time.Since(start)
+			go func() {
+				<-done // Keep the lines in order.
+				log.Info("Session disconnected", append(fields, "duration", duration)...)
+			}()
+		}
+	}
+}
+
+// teaMiddleware runs the Bubble Tea program of every session like
+// bubbletea.MiddlewareWithProgramHandler, except that programs stopped by
+// their session ending, as they are given its context, aren't logged as
+// failing. exited is given the final model of the program.
+func teaMiddleware(handler func(ssh.Session) *tea.Program, exited func(ssh.Session, tea.Model)) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			_, windows, ok := s.Pty()
+			if !ok {
+				wish.Fatalln(s, "no active terminal, skipping")
+				return
+			}
+			// The handler already answered the sessions it has no program for.
+			p := handler(s)
+			if p == nil {
+				return
+			}
+			ctx, cancel := context.WithCancel(s.Context())
+			go func() {
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case w, ok := <-windows:
+						if !ok {
+							return
+						}
+						p.Send(tea.WindowSizeMsg{Width: w.Width, Height: w.Height})
+					}
+				}
+			}()
+			m, err := p.Run()
+			if err != nil && !errors.Is(err, tea.ErrProgramKilled) {
+				log.Error("Program exited with error", "error", err)
+			}
+			exited(s, m)
+			// Restores the terminal if the program crashed.
+			p.Kill()
+			cancel()
+			next(s)
+		}
+	}
+}
+
+// recoverMiddleware recovers panics from the handlers down the chain, logging
+// the stack trace and closing the affected session instead of crashing the
+// whole server.
+func recoverMiddleware() wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Error("Recovered from panic", "remote", s.RemoteAddr().String(), "error", r, "stack", string(debug.Stack()))
+					_ = s.Exit(1)
+					_ = s.Close()
+				}
+			}()
+			next(s)
+		}
+	}
+}
+
+// disconnect restores the client terminal from the alt screen, prints msg and
+// closes the session.
+func disconnect(s ssh.Session, msg string) {
+	out := termenv.NewOutput(s)
+	out.ExitAltScreen()
+	out.ShowCursor()
+	wish.Println(s, msg+"\r")
+	_ = s.Exit(1)
+	_ = s.Close()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// TestRecoverMiddleware panics in the first session of a server and checks
+// the server still serves the next one.
+func TestRecoverMiddleware(t *testing.T) {
+	var sessions atomic.Int32
+	handler := func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			if sessions.Add(1) == 1 {
+				panic("first session")
+			}
+			wish.Print(s, "served")
+		}
+	}
+	hostKeys, err := hostKeyOptions(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := wish.NewServer(append(hostKeys, wish.WithMiddleware(handler, recoverMiddleware()))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Close()
+
+	client, err := gossh.Dial("tcp", l.Addr().String(), &gossh.ClientConfig{
+		User:            "visitor",
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	for i, want := range []string{"", "served"} {
+		session, err := client.NewSession()
+		if err != nil {
+			t.Fatalf("session %d: %v", i+1, err)
+		}
+		out, err := session.Output("")
+		if i == 0 {
+			var exit *gossh.ExitError
+			if !errors.As(err, &exit) || exit.ExitStatus() != 1 {
+				t.Errorf("session 1 ended with %v, want exit status 1", err)
+			}
+		} else if err != nil {
+			t.Errorf("session %d: %v", i+1, err)
+		}
+		if string(out) != want {
+			t.Errorf("session %d wrote %q, want %q", i+1, out, want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// menuTop is the row the menu body starts at, below the top margin.
+const menuTop = 1
+
+// updateMouse scrolls the menu, resume and changelog with the wheel, and opens the menu
+// item that is clicked.
+func (m model) updateMouse(msg tea.MouseMsg) (model, tea.Cmd) {
+	if m.tooSmall || m.showHelp || msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+	switch m.state {
+	case stateResume:
+		var cmd tea.Cmd
+		m.resume, cmd = m.resume.Update(msg)
+		return m, cmd
+	case stateChangelog:
+		var cmd tea.Cmd
+		m.changelog, cmd = m.changelog.Update(msg)
+		return m, cmd
+	case stateMenu:
+	default:
+		return m, nil
+	}
+
+	body, line := m.menuBody()
+	overflows := m.menuOverflows(body)
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		if overflows {
+			m.menu.LineUp(3)
+		}
+	case tea.MouseButtonWheelDown:
+		if overflows {
+			m.menu.LineDown(3)
+		}
+	case tea.MouseButtonLeft:
+		row := msg.Y - menuTop - m.borderOffset()
+		if overflows {
+			if row >= m.menu.Height {
+				return m, nil
+			}
+			row += m.menu.YOffset
+		}
+		choice := row - (line - m.Choice)
+		if label, _ := m.choiceLink(choice); label == "" {
+			return m, nil
+		}
+		m.Choice = choice
+		m = m.scrollMenu()
+		return m.openChoice()
+	}
+	return m, nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+type sessionIDKey struct{}
+
+// sessionInfo describes a connected session.
+type sessionInfo struct {
+	ID          string
+	User        string
+	Fingerprint string
+	ConnectedAt time.Time
+}
+
+// kickGrace is how long a kicked session has to say goodbye before it's
+// closed.
+const kickGrace = 2 * time.Second
+
+// sessionRegistry keeps track of the connected sessions, and of the Bubble
+// Tea programs running in the interactive ones.
+type sessionRegistry struct {
+	mu       sync.RWMutex
+	sessions map[string]sessionInfo
+	conns    map[string]ssh.Session
+	programs map[string]*tea.Program
+}
+
+func newSessionRegistry() *sessionRegistry {
+	return &sessionRegistry{
+		sessions: make(map[string]sessionInfo),
+		conns:    make(map[string]ssh.Session),
+		programs: make(map[string]*tea.Program),
+	}
+}
+
+func (r *sessionRegistry) add(info sessionInfo, s ssh.Session) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[info.ID] = info
+	r.conns[info.ID] = s
+}
+
+func (r *sessionRegistry) remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, id)
+	delete(r.conns, id)
+	delete(r.programs, id)
+}
+
+// send sends msg to the program running in the session with the given id,
+// reporting whether there's one.
+func (r *sessionRegistry) send(id string, msg tea.Msg) bool {
+	r.mu.RLock()
+	p, ok := r.programs[id]
+	r.mu.RUnlock()
+	if ok {
+		go p.Send(msg)
+	}
+	return ok
+}
+
+// kick disconnects the session with the given id, letting its program say
+// goodbye first. It reports whether the session was still connected.
+func (r *sessionRegistry) kick(id string) bool {
+	r.mu.RLock()
+	s, ok := r.conns[id]
+	p := r.programs[id]
+	r.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	if p == nil {
+		_ = s.Close()
+		return true
+	}
+	go p.Send(kickedMsg{})
+	time.AfterFunc(kickGrace, func() { _ = s.Close() })
+	return true
+}
+
+// setProgram registers the program running in the session with the given id,
+// it's unregistered along with the session.
+func (r *sessionRegistry) setProgram(id string, p *tea.Program) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sessions[id]; ok {
+		r.programs[id] = p
+	}
+}
+
+// broadcast sends msg to every running program, giving up when ctx is done.
+func (r *sessionRegistry) broadcast(ctx context.Context, msg tea.Msg) {
+	r.mu.RLock()
+	var wg sync.WaitGroup
+	for _, p := range r.programs {
+		wg.Add(1)
+		go func(p *tea.Program) {
+			defer wg.Done()
+			p.Send(msg)
+		}(p)
+	}
+	r.mu.RUnlock()
+
+	sent := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-ctx.Done():
+	}
+}
+
+// list returns the connected sessions, longest connected first.
+func (r *sessionRegistry) list() []sessionInfo {
+	r.mu.RLock()
+	sessions := make([]sessionInfo, 0, len(r.sessions))
+	for _, info := range r.sessions {
+		sessions = append(sessions, info)
+	}
+	r.mu.RUnlock()
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].ConnectedAt.Before(sessions[j].ConnectedAt)
+	})
+	return sessions
+}
+
+// registryMiddleware registers every session for its whole lifetime. The
+// session ID is stored in the session context under sessionIDKey.
+func registryMiddleware(r *sessionRegistry) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			id := newSessionID()
+			s.Context().SetValue(sessionIDKey{}, id)
+			r.add(sessionInfo{
+				ID:          id,
+				User:        sanitize(s.User(), guestbookMaxName),
+				Fingerprint: fingerprint(s),
+				ConnectedAt: time.Now(),
+			}, s)
+			defer r.remove(id)
+			next(s)
+		}
+	}
+}
+
+func newSessionID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// sessionID returns the ID registryMiddleware assigned to s.
+func sessionID(s ssh.Session) string {
+	id, _ := s.Context().Value(sessionIDKey{}).(string)
+	return id
+}
+
+// fingerprint returns the SHA256 fingerprint of the public key the client
+// offered, or an empty string if it didn't offer any.
+func fingerprint(s ssh.Session) string {
+	if pk := s.PublicKey(); pk != nil {
+		return gossh.FingerprintSHA256(pk)
+	}
+	return ""
+}
+
+type onlineTickMsg struct{}
+
+func onlineTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return onlineTickMsg{}
+	})
+}
+
+func (m model) onlineView() string {
+	sessions := m.online.list()
+	title := m.aboutNameStyle.Render(fmt.Sprintf("Who's online (%d)", len(sessions)))
+	tpl := m.hintLine()
+
+	var b strings.Builder
+	for _, info := range sessions {
+		line := fmt.Sprintf("%-8s  %s  %s", info.ID, padRight(info.User, 16), time.Since(info.ConnectedAt).Truncate(time.Second))
+		if info.ID == m.sessionID {
+			b.WriteString(m.checkboxStyle.Render(line+"  (you)") + "\n")
+		} else {
+			b.WriteString(m.aboutStyle.Render(line) + "\n")
+		}
+	}
+
+	s := fmt.Sprintf("%s\n\n%s\n%s", title, b.String(), tpl)
+	return m.mainStyle.Render("\n" + s + "\n\n")
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/url"
+	"os/exec"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+// openOnServer opens url in a browser of the host the card runs on, with
+// the opener of its OS. It's only used with SSH_OPEN_URLS, see openChoice.
+var openOnServer = func(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("xdg-open", url)
+	case "darwin":
+		cmd = exec.Command("open", url)
+	default:
+		cmd = exec.Command("cmd", "/c", "start", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// openURL opens url on the server, logging if it can't.
+func openURL(url string) tea.Cmd {
+	return func() tea.Msg {
+		if err := openOnServer(url); err != nil {
+			log.Warn("Could not open URL on the server", "url", url, "error", err)
+		}
+		return nil
+	}
+}
+
+// isWebURL reports whether s is a link a browser opens, unlike the mailto
+// one of the contact item.
+func isWebURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// run runs cmd and the commands it batches.
+func run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		for _, cmd := range batch {
+			run(cmd)
+		}
+	}
+}
+
+func TestOpenURLs(t *testing.T) {
+	t.Setenv("SSH_CONFIG", "")
+	for env, want := range map[string]bool{"": false, "false": false, "true": true} {
+		t.Setenv("SSH_OPEN_URLS", env)
+		cfg, err := loadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.OpenURLs != want {
+			t.Errorf("SSH_OPEN_URLS=%q opens links on the server: %t, want %t", env, cfg.OpenURLs, want)
+		}
+	}
+
+	defer func(open func(string) error) { openOnServer = open }(openOnServer)
+	tests := []struct {
+		name     string
+		openURLs bool
+		label    string
+		want     string
+	}{
+		{"off", false, "GitHub", ""},
+		{"off for the resume", false, "Resume / CV", ""},
+		{"on", true, "GitHub", GITHUB_URL},
+		{"on for the resume", true, "Resume / CV", RESUME_URL},
+		{"on for the contact", true, "Contact", ""},
+		{"on for a view", true, "Skills", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opened string
+			openOnServer = func(url string) error {
+				opened = url
+				return nil
+			}
+			m := choose(t, newTestModel(t, 80, 24), tt.label)
+			m.openURLs = tt.openURLs
+			m, cmd := press(m, "enter")
+			run(cmd)
+			if opened != tt.want {
+				t.Errorf("opened %q on the server, want %q", opened, tt.want)
+			}
+			if m.state == stateMenu {
+				t.Error("the link wasn't shown to the visitor")
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/paginator"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pager splits the lists of the card into pages, keeping track of the
+// selected item so it's always on the current page.
+type pager struct {
+	paginator.Model
+	selected int
+}
+
+// newPager returns a pager turning pages with left/right and h/l.
+func newPager() pager {
+	return pager{Model: paginator.New()}
+}
+
+// newPagerWithKeys returns a pager turning pages with the given keys, for
+// views where left/right and h/l are taken, like text inputs.
+func newPagerWithKeys(prev, next []string) pager {
+	p := newPager()
+	p.KeyMap = paginator.KeyMap{
+		PrevPage: key.NewBinding(key.WithKeys(prev...)),
+		NextPage: key.NewBinding(key.WithKeys(next...)),
+	}
+	return p
+}
+
+// resize fits total items in pages of perPage, keeping the selected item
+// on the current page.
+func (p pager) resize(perPage, total int) pager {
+	p.PerPage = max(perPage, 1)
+	p.SetTotalPages(total)
+	p.selected = max(min(p.selected, total-1), 0)
+	p.Page = p.selected / p.PerPage
+	return p
+}
+
+// Update turns the page on the pager keys, selecting the first item of the
+// new page.
+func (p pager) Update(msg tea.Msg) (pager, tea.Cmd) {
+	page := p.Page
+	var cmd tea.Cmd
+	p.Model, cmd = p.Model.Update(msg)
+	if p.Page != page {
+		p.selected = p.Page * p.PerPage
+	}
+	return p, cmd
+}
+
+// move moves the selection by delta items out of total, turning the page
+// when it leaves the current one.
+func (p pager) move(delta, total int) pager {
+	p.selected = max(min(p.selected+delta, total-1), 0)
+	p.Page = p.selected / p.PerPage
+	return p
+}
+
+// View renders the current page like "page 2/5", or nothing if there's only
+// one page.
+func (p pager) View() string {
+	if p.TotalPages <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("page %d/%d", p.Page+1, p.TotalPages)
+}
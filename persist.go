@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+)
+
+// backupSuffix is appended to the path of a state file for its previous
+// version.
+const backupSuffix = ".bak"
+
+// writeFileAtomic replaces the file at path with data so that a crash leaves
+// either the old or the new content, never a mix of the two. data is synced
+// to a temporary file next to path which is then renamed over it, after the
+// previous version is moved to the backup.
+func writeFileAtomic(path string, data []byte, perm fs.FileMode) error {
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // Fails once renamed.
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(path, path+backupSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	// Sync the directory too so the renames survive a power loss.
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// loadStateFile reads the file at path and hands it to parse. If it's
+// missing, unreadable or parse fails, the backup left by writeFileAtomic is
+// tried instead. The error of the file at path is returned when neither
+// could be loaded, fs.ErrNotExist if there's no file at all.
+func loadStateFile(path string, parse func([]byte) error) error {
+	err := readStateFile(path, parse)
+	if err == nil {
+		return nil
+	}
+	backup := path + backupSuffix
+	berr := readStateFile(backup, parse)
+	if berr != nil {
+		switch {
+		case errors.Is(berr, fs.ErrNotExist):
+			return err
+		case errors.Is(err, fs.ErrNotExist):
+			return berr
+		}
+		return errors.Join(err, berr)
+	}
+	log.Warn("Recovered state from the backup", "path", path, "backup", backup, "error", err)
+	return nil
+}
+
+func readStateFile(path string, parse func([]byte) error) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := parse(data); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
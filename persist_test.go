@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWriteFileAtomicKeepsBackup checks the previous version written is kept
+// and loaded when the file is cut short, like by a full disk.
+func TestWriteFileAtomicKeepsBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	for _, data := range []string{`{"v":1}`, `{"v":2}`} {
+		if err := writeFileAtomic(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for file, want := range map[string]string{path: `{"v":2}`, path + backupSuffix: `{"v":1}`} {
+		if got, err := os.ReadFile(file); err != nil || string(got) != want {
+			t.Errorf("%s has %q (%v), want %q", filepath.Base(file), got, err, want)
+		}
+	}
+
+	if err := os.Truncate(path, 3); err != nil {
+		t.Fatal(err)
+	}
+	var state struct{ V int }
+	if err := loadStateFile(path, func(data []byte) error { return json.Unmarshal(data, &state) }); err != nil {
+		t.Fatal(err)
+	}
+	if state.V != 1 {
+		t.Errorf("loaded %d from the truncated file, want the backup's 1", state.V)
+	}
+}
+
+func TestLoadStateFile(t *testing.T) {
+	const valid, truncated = `{"v":1}`, `{"v":`
+	tests := []struct {
+		name         string
+		main, backup string // not written when empty
+		want         int
+		wantErr      bool
+	}{
+		{"main", valid, "", 1, false},
+		{"truncated with a backup", truncated, `{"v":2}`, 2, false},
+		{"missing with a backup", "", `{"v":2}`, 2, false},
+		{"truncated without a backup", truncated, "", 0, true},
+		{"both truncated", truncated, truncated, 0, true},
+		{"none", "", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "state.json")
+			for file, data := range map[string]string{path: tt.main, path + backupSuffix: tt.backup} {
+				if data == "" {
+					continue
+				}
+				if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var state struct{ V int }
+			err := loadStateFile(path, func(data []byte) error { return json.Unmarshal(data, &state) })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %t", err, tt.wantErr)
+			}
+			if state.V != tt.want {
+				t.Errorf("loaded %d, want %d", state.V, tt.want)
+			}
+			if tt.main == "" && tt.backup == "" && !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("err = %v, want fs.ErrNotExist", err)
+			}
+		})
+	}
+}
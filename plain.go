@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/muesli/termenv"
+)
+
+// setPlain turns the plain mode on or off. Plain sessions are rendered
+// without colors or styles, outside of the alt screen and without
+// animations, so they read well with screen readers.
+func (m model) setPlain(on bool) (model, tea.Cmd) {
+	m.plain = on
+	profile := m.colorProfile
+	if on {
+		profile = termenv.Ascii
+		m.typing = false
+	}
+	m.renderer.SetColorProfile(profile)
+	m = m.withTheme(m.theme).resize(m.windowWidth, m.windowHeight)
+	switch {
+	case m.inline:
+		return m, nil
+	case on:
+		return m, tea.Batch(tea.ExitAltScreen, tea.DisableMouse)
+	}
+	return m, tea.Batch(tea.EnterAltScreen, tea.EnableMouseCellMotion)
+}
+
+// plainView renders the card as lines of text, the about text followed by
+// the numbered menu items.
+func (m model) plainView() string {
+	before, after := m.aboutParts()
+	var b strings.Builder
+	if m.tagline != "" {
+		b.WriteString(m.tagline + "\n\n")
+	}
+	b.WriteString(before + myName + after + "\n\n")
+	for i, item := range m.items {
+		marker := "  "
+		if i == m.Choice {
+			marker = "> "
+		}
+		fmt.Fprintf(&b, "%s%d. %s", marker, i+1, m.tr.label(item.label))
+		if item.display != "" {
+			b.WriteString(": " + item.display)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\nKeys: j/k select, enter open, ? help, P leave plain mode, q quit.")
+	if m.status != "" {
+		b.WriteString(" " + m.status)
+	}
+	return wordwrap.String(b.String(), max(m.Width-1, minWidth)) + "\n"
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+
+	"github.com/charmbracelet/log"
+)
+
+// prefs are the preferences remembered for a returning visitor.
+type prefs struct {
+	Theme  string `json:"theme,omitempty"`
+	Choice int    `json:"choice,omitempty"`
+}
+
+// prefStore maps public key fingerprints to visitor preferences, persisted
+// as JSON.
+type prefStore struct {
+	path string
+
+	mu    sync.Mutex
+	prefs map[string]prefs
+
+	// saveMu serializes writes of the file.
+	saveMu sync.Mutex
+}
+
+// loadPrefStore reads the preferences from path, or its backup if it's
+// damaged, starting empty if the file doesn't exist yet.
+func loadPrefStore(path string) (*prefStore, error) {
+	s := &prefStore{path: path, prefs: make(map[string]prefs)}
+	err := loadStateFile(path, func(data []byte) error {
+		p := make(map[string]prefs)
+		if err := json.Unmarshal(data, &p); err != nil {
+			return err
+		}
+		s.prefs = p
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("load prefs: %w", err)
+	}
+	return s, nil
+}
+
+// get returns the preferences of the visitor with the given fingerprint.
+func (s *prefStore) get(fingerprint string) prefs {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.prefs[fingerprint]
+}
+
+// update applies fn to the preferences of the visitor with the given
+// fingerprint and saves them in the background.
+func (s *prefStore) update(fingerprint string, fn func(*prefs)) {
+	if fingerprint == "" {
+		return
+	}
+	s.mu.Lock()
+	p := s.prefs[fingerprint]
+	fn(&p)
+	s.prefs[fingerprint] = p
+	s.mu.Unlock()
+	go s.save()
+}
+
+func (s *prefStore) save() {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	data, err := json.Marshal(s.prefs)
+	s.mu.Unlock()
+	if err == nil {
+		err = writeFileAtomic(s.path, data, 0o644)
+	}
+	if err != nil {
+		log.Error("Could not save prefs", "error", err)
+	}
+}
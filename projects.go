@@ -0,0 +1,266 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+const (
+	projectsTTL      = time.Hour
+	projectsRetry    = time.Minute
+	projectsShown    = 30
+	projectsEndpoint = "https://api.github.com/users/%s/repos?per_page=100&type=owner"
+)
+
+type repo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Stars       int    `json:"stargazers_count"`
+	Language    string `json:"language"`
+	Fork        bool   `json:"fork"`
+}
+
+// projectCache holds the top repositories of a GitHub user. Stale results are
+// served while they're refreshed in the background, so only the first visitor
+// after a restart waits on the API.
+type projectCache struct {
+	user   string
+	client *http.Client
+
+	mu         sync.Mutex
+	repos      []repo
+	fetched    time.Time
+	err        error
+	failed     time.Time
+	refreshing bool
+	fetching   chan struct{} // closed once the first fetch in flight is done
+}
+
+func newProjectCache(user string) *projectCache {
+	return &projectCache{user: user, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// get returns the cached repositories, fetching them if there are none yet.
+// The fetch is made with ctx, the context of the session asking, so it's
+// cancelled along with the session.
+func (c *projectCache) get(ctx context.Context) ([]repo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// The API is called without holding c.mu, sessions asking meanwhile
+	// wait for the fetch in flight instead of starting another, and start
+	// their own if it was cancelled.
+	for c.fetching != nil {
+		wait := c.fetching
+		c.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			c.mu.Lock()
+			return nil, ctx.Err()
+		}
+		c.mu.Lock()
+	}
+	if c.repos != nil {
+		if time.Since(c.fetched) > projectsTTL && !c.refreshing {
+			c.refreshing = true
+			go c.refresh()
+		}
+		return c.repos, nil
+	}
+	// Don't retry on every session while the API is failing.
+	if c.err != nil && time.Since(c.failed) < projectsRetry {
+		return nil, c.err
+	}
+	done := make(chan struct{})
+	c.fetching = done
+	c.mu.Unlock()
+	repos, err := c.fetch(ctx)
+	c.mu.Lock()
+	c.fetching = nil
+	close(done)
+	// The session leaving isn't the API failing.
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	c.store(repos, err)
+	return c.repos, c.err
+}
+
+// refresh fetches the repositories again in the background, for no session
+// in particular.
+func (c *projectCache) refresh() {
+	repos, err := c.fetch(context.Background())
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshing = false
+	c.store(repos, err)
+}
+
+// store saves the result of a fetch, keeping the previous repositories if it
+// failed. c.mu must be held.
+func (c *projectCache) store(repos []repo, err error) {
+	if err != nil {
+		log.Warn("Could not fetch GitHub projects", "user", c.user, "error", err)
+		c.err = err
+		c.failed = time.Now()
+		return
+	}
+	c.repos = repos
+	c.fetched = time.Now()
+	c.err = nil
+}
+
+// fetch returns the projectsShown most starred repositories of the user,
+// leaving out forks.
+func (c *projectCache) fetch(ctx context.Context) ([]repo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(projectsEndpoint, url.PathEscape(c.user)), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "ssh-card")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("github api: %s", resp.Status)
+	}
+
+	var all []repo
+	if err := json.NewDecoder(resp.Body).Decode(&all); err != nil {
+		return nil, fmt.Errorf("decode github repos: %w", err)
+	}
+	repos := make([]repo, 0, len(all))
+	for _, r := range all {
+		if !r.Fork {
+			repos = append(repos, r)
+		}
+	}
+	slices.SortStableFunc(repos, func(a, b repo) int { return cmp.Compare(b.Stars, a.Stars) })
+	return repos[:min(len(repos), projectsShown)], nil
+}
+
+type projectsMsg struct {
+	repos []repo
+}
+
+// loadProjects gets the projects from c, giving up once ctx is done.
+func loadProjects(ctx context.Context, c *projectCache) tea.Cmd {
+	return func() tea.Msg {
+		var repos []repo
+		var err error
+		traced(ctx, "projects.load", func() error {
+			repos, err = c.get(ctx)
+			return err
+		})
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			return errorMsg{stateProjects, "Couldn't load my GitHub projects", err}
+		}
+		return projectsMsg{repos}
+	}
+}
+
+// loading reports whether the current view is waiting on remote data.
+func (m model) loading() bool {
+	return m.state == stateProjects && m.projects == nil
+}
+
+// paginateProjects fits the pages of the projects to the window.
+func (m model) paginateProjects() model {
+	// Every project takes up to three lines.
+	m.projectPages = m.projectPages.resize((m.bodyHeight()-9)/3, len(m.projectMatches()))
+	return m
+}
+
+// projectMatches returns the indices of the projects matching the filter.
+func (m model) projectMatches() []int {
+	if m.projects == nil {
+		return nil
+	}
+	repos := m.projects.repos
+	return m.filter.indices(len(repos), func(i int) []string { return []string{repos[i].Name, repos[i].Description} })
+}
+
+// showProjects switches to the projects view, loading them with a spinner.
+func (m model) showProjects() (model, tea.Cmd) {
+	m.state = stateProjects
+	m.projects = nil
+	m.projectPages = newPager()
+	m.filter = newListFilter()
+	m.spinner = spinner.New(spinner.WithSpinner(m.glyphs.spinner), spinner.WithStyle(m.checkboxStyle))
+	return m, tea.Batch(loadProjects(m.ctx, m.repos), m.spinner.Tick)
+}
+
+// updateProjects filters the projects and turns their pages.
+func (m model) updateProjects(msg tea.KeyMsg) (model, tea.Cmd) {
+	if filter, cmd, ok := m.filter.Update(msg); ok {
+		m.filter = filter
+		return m.paginateProjects(), cmd
+	}
+	if s := msg.String(); s == "esc" || s == "backspace" {
+		m.state = stateMenu
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.projectPages, cmd = m.projectPages.Update(msg)
+	return m, cmd
+}
+
+func (m model) projectsView() string {
+	title := m.aboutNameStyle.Render("Projects")
+	tpl := m.hintLine()
+
+	var b strings.Builder
+	if f := m.filter.View(); f != "" {
+		b.WriteString(f + "\n\n")
+	}
+	matches := m.projectMatches()
+	switch {
+	case m.projects == nil:
+		b.WriteString(m.spinner.View() + m.subtleStyle.Render("Loading"+m.glyphs.ellipsis))
+	case len(m.projects.repos) == 0:
+		b.WriteString(m.subtleStyle.Render("No public projects yet."))
+	case len(matches) == 0:
+		b.WriteString(m.subtleStyle.Render("No projects match."))
+	}
+	if m.projects != nil {
+		start, end := m.projectPages.GetSliceBounds(len(matches))
+		for i, j := range matches[start:end] {
+			r := m.projects.repos[j]
+			if i > 0 {
+				b.WriteString("\n\n")
+			}
+			b.WriteString(m.filter.highlight(r.Name, m.aboutNameStyle, m.matchStyle()) + " " + m.checkboxStyle.Render(fmt.Sprintf("%s %d", m.glyphs.star, r.Stars)))
+			if r.Language != "" {
+				b.WriteString(m.dotStyle + languageStyle(m.subtleStyle, r.Language).Render(r.Language))
+			}
+			if r.Description != "" {
+				desc := m.filter.highlight(r.Description, m.aboutStyle, m.matchStyle())
+				b.WriteString("\n" + m.renderer.NewStyle().Width(max(m.Width-4, 20)).MaxHeight(1).Render(desc))
+			}
+		}
+		if page := m.projectPages.View(); page != "" {
+			b.WriteString("\n\n" + m.subtleStyle.Render(page))
+		}
+	}
+
+	s := fmt.Sprintf("%s\n\n%s\n\n%s", title, b.String(), tpl)
+	return m.mainStyle.Render("\n" + s + "\n\n")
+}
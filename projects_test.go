@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// blockingTransport answers every request with body once release is closed,
+// counting the requests and telling started about them.
+type blockingTransport struct {
+	body     string
+	started  chan struct{}
+	release  chan struct{}
+	requests atomic.Int32
+}
+
+func (t *blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	t.started <- struct{}{}
+	<-t.release
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(t.body)), Request: req}, nil
+}
+
+func TestProjectCacheFetchesOnceWithoutLocking(t *testing.T) {
+	transport := &blockingTransport{
+		body:    `[{"name":"card","stargazers_count":3},{"name":"fork","fork":true}]`,
+		started: make(chan struct{}, 5),
+		release: make(chan struct{}),
+	}
+	c := newProjectCache("me")
+	c.client = &http.Client{Transport: transport}
+
+	var wg sync.WaitGroup
+	results := make([][]repo, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			repos, err := c.get(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = repos
+		}()
+	}
+	<-transport.started
+	// The lock is free while the API is called.
+	c.mu.Lock()
+	c.mu.Unlock()
+	close(transport.release)
+	wg.Wait()
+
+	if n := transport.requests.Load(); n != 1 {
+		t.Errorf("%d requests to the API, want 1", n)
+	}
+	for i, repos := range results {
+		if len(repos) != 1 || repos[0].Name != "card" {
+			t.Errorf("get %d returned %v, want card", i, repos)
+		}
+	}
+}
+
+// serverTransport sends every request to the server at url instead.
+type serverTransport struct{ url *url.URL }
+
+func (t serverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.url.Scheme, t.url.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// TestProjectsCancelledWithSession opens the projects of a GitHub which never
+// answers, then ends the session: the request must be cancelled and the
+// program stop right away.
+func TestProjectsCancelledWithSession(t *testing.T) {
+	started, cancelled := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		select {
+		case <-r.Context().Done():
+			close(cancelled)
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, endSession := context.WithCancel(context.Background())
+	m := newTestModel(t, 80, 24)
+	m.ctx = ctx
+	m.repos = newProjectCache("me")
+	m.repos.client = &http.Client{Transport: serverTransport{u}}
+	p := tea.NewProgram(m, tea.WithContext(ctx), tea.WithInput(nil), tea.WithOutput(io.Discard), tea.WithoutSignalHandler())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		_, _ = p.Run()
+	}()
+	p.Send(keyMsg("p"))
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the projects were never requested")
+	}
+	endSession()
+
+	for name, done := range map[string]chan struct{}{"request": cancelled, "program": stopped} {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Errorf("the %s went on after the session ended", name)
+		}
+	}
+}
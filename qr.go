@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mdp/qrterminal/v3"
+)
+
+// qrConfigs are tried in order until the rendered code fits the terminal,
+// going from full blocks down to compact half blocks with a thin quiet zone.
+var qrConfigs = []qrterminal.Config{
+	{Level: qrterminal.L, BlackChar: "  ", WhiteChar: "██", QuietZone: 2},
+	{Level: qrterminal.L, HalfBlocks: true, QuietZone: 2},
+	{Level: qrterminal.L, HalfBlocks: true, QuietZone: 1},
+}
+
+// renderQR renders url as a QR code fitting within width x height cells. If
+// none of the configs fit, the most compact one is returned anyway.
+func renderQR(url string, width, height int) string {
+	var code string
+	for _, c := range qrConfigs {
+		var b strings.Builder
+		c.Writer = &b
+		qrterminal.GenerateWithConfig(url, c)
+		code = strings.TrimRight(b.String(), "\n")
+		if lipgloss.Width(code) <= width && lipgloss.Height(code) <= height {
+			break
+		}
+	}
+	return code
+}
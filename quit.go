@@ -0,0 +1,37 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// quit quits, or asks the visitor to confirm first when confirmQuit is set.
+func (m model) quit() (model, tea.Cmd) {
+	if m.confirmQuit {
+		m.confirmingQuit = true
+		return m, nil
+	}
+	return m, tea.Quit
+}
+
+// updateQuit handles the keys of the quit prompt, going back to the card
+// unless the visitor confirms.
+func (m model) updateQuit(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		return m, tea.Quit
+	case "n", "N", "esc", "q":
+		m.confirmingQuit = false
+	}
+	return m, nil
+}
+
+// quitView renders the quit prompt in a box centered over a dimmed
+// background, like the help.
+func (m model) quitView() string {
+	box := m.helpStyle.Render(m.aboutNameStyle.Render("Quit? (y/n)") + "\n\n" + m.hintLine())
+	return lipgloss.Place(m.Width, m.bodyHeight(), lipgloss.Center, lipgloss.Center, box,
+		lipgloss.WithWhitespaceChars(m.glyphs.shade),
+		lipgloss.WithWhitespaceForeground(m.dimColor),
+	)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// rateLimiter allows up to limit connections per source IP within a sliding
+// window.
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string][]time.Time
+}
+
+// newRateLimiter returns a rateLimiter which periodically forgets IPs that
+// didn't connect within the window, so the map stays bounded.
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	l := &rateLimiter{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string][]time.Time),
+	}
+	go func() {
+		for range time.Tick(window) {
+			l.cleanup()
+		}
+	}()
+	return l
+}
+
+// setLimit changes the number of connections allowed per IP within the
+// window, applying to the connections already recorded as well.
+func (l *rateLimiter) setLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+}
+
+// allow records a connection from ip and reports whether it's within the
+// limit.
+func (l *rateLimiter) allow(ip string) bool {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hits := recentHits(l.hits[ip], now.Add(-l.window))
+	if len(hits) >= l.limit {
+		l.hits[ip] = hits
+		return false
+	}
+	l.hits[ip] = append(hits, now)
+	return true
+}
+
+func (l *rateLimiter) cleanup() {
+	since := time.Now().Add(-l.window)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ip, hits := range l.hits {
+		if hits = recentHits(hits, since); len(hits) == 0 {
+			delete(l.hits, ip)
+		} else {
+			l.hits[ip] = hits
+		}
+	}
+}
+
+// recentHits drops the hits older than since, hits are sorted oldest first.
+func recentHits(hits []time.Time, since time.Time) []time.Time {
+	i := 0
+	for i < len(hits) && hits[i].Before(since) {
+		i++
+	}
+	return hits[i:]
+}
+
+// rateLimitMiddleware rejects sessions from IPs connecting more often than
+// the limiter allows.
+func rateLimitMiddleware(l *rateLimiter) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			ip := remoteIP(s)
+			if !l.allow(ip) {
+				log.Warn("Rate limited connection", "remote", ip)
+				setResult(s, resultRateLimited)
+				wish.Fatalln(s, "Too many connections, try again later.")
+				return
+			}
+			next(s)
+		}
+	}
+}
+
+// remoteIP returns the IP address of the client without the port.
+func remoteIP(s ssh.Session) string {
+	host, _, err := net.SplitHostPort(s.RemoteAddr().String())
+	if err != nil {
+		return s.RemoteAddr().String()
+	}
+	return host
+}
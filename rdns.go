@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	rdnsTimeout = time.Second
+	rdnsTTL     = time.Hour
+)
+
+type rdnsEntry struct {
+	host    string
+	expires time.Time
+}
+
+// reverseDNS resolves the IPs of visitors to hostnames for the logs, caching
+// the answers for rdnsTTL. Expired entries are cleaned up periodically so
+// the cache stays bounded.
+type reverseDNS struct {
+	mu    sync.Mutex
+	cache map[string]rdnsEntry
+}
+
+func newReverseDNS() *reverseDNS {
+	r := &reverseDNS{cache: make(map[string]rdnsEntry)}
+	go func() {
+		for range time.Tick(rdnsTTL) {
+			r.cleanup()
+		}
+	}()
+	return r
+}
+
+// lookup returns the hostname of ip, or ip itself if it has none or it
+// couldn't be resolved within rdnsTimeout. Failures are cached too, so a
+// slow resolver is only waited on once per IP.
+func (r *reverseDNS) lookup(ip string) string {
+	now := time.Now()
+	r.mu.Lock()
+	e, ok := r.cache[ip]
+	r.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.host
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rdnsTimeout)
+	defer cancel()
+	host := ip
+	if names, err := net.DefaultResolver.LookupAddr(ctx, ip); err == nil && len(names) > 0 {
+		host = strings.TrimSuffix(names[0], ".")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache[ip] = rdnsEntry{host, now.Add(rdnsTTL)}
+	return host
+}
+
+func (r *reverseDNS) cleanup() {
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for ip, e := range r.cache {
+		if !now.Before(e.expires) {
+			delete(r.cache, ip)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+)
+
+const (
+	defaultRestoreTTL = 10 * time.Minute
+	// welcomeBackTimeout is how long visitors are told their view was
+	// restored.
+	welcomeBackTimeout = 5 * time.Second
+)
+
+// viewToken is the view a visitor left the card in, restored if they
+// reconnect with the same key before it expires.
+type viewToken struct {
+	state   viewState
+	choice  int
+	post    int // index of the post being read in the blog
+	offset  int // scroll position of the resume, changelog or post
+	name    string
+	expires time.Time
+}
+
+// viewTokens holds the views of the visitors who recently disconnected, by
+// public key fingerprint. Expired tokens are cleaned up periodically. A nil
+// viewTokens restores nothing.
+type viewTokens struct {
+	ttl time.Duration
+
+	mu     sync.Mutex
+	tokens map[string]viewToken
+}
+
+func newViewTokens(ttl time.Duration) *viewTokens {
+	t := &viewTokens{ttl: ttl, tokens: make(map[string]viewToken)}
+	go func() {
+		for range time.Tick(ttl) {
+			t.cleanup()
+		}
+	}()
+	return t
+}
+
+// put saves tok for the visitor with the given fingerprint, for the TTL.
+func (t *viewTokens) put(fingerprint string, tok viewToken) {
+	if t == nil || fingerprint == "" {
+		return
+	}
+	tok.expires = time.Now().Add(t.ttl)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tokens[fingerprint] = tok
+}
+
+// take returns and removes the token of the visitor with the given
+// fingerprint, if it hasn't expired.
+func (t *viewTokens) take(fingerprint string) (viewToken, bool) {
+	if t == nil || fingerprint == "" {
+		return viewToken{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tok, ok := t.tokens[fingerprint]
+	delete(t.tokens, fingerprint)
+	return tok, ok && time.Now().Before(tok.expires)
+}
+
+func (t *viewTokens) cleanup() {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for fp, tok := range t.tokens {
+		if !now.Before(tok.expires) {
+			delete(t.tokens, fp)
+		}
+	}
+}
+
+// viewToken returns the view the visitor is in, or false for the views not
+// worth restoring, like the menu or a game of snake.
+func (m model) viewToken() (viewToken, bool) {
+	tok := viewToken{state: m.state, choice: m.Choice, name: m.visitorName}
+	switch m.state {
+	case stateLink, stateQR, stateContact, stateBlog, stateOnline, stateStats, stateProjects, stateSkills, stateShare:
+	case stateResume:
+		tok.offset = m.resume.YOffset
+	case stateChangelog:
+		tok.offset = m.changelog.YOffset
+	case statePost:
+		tok.post = m.blogMatches()[m.blogPages.selected]
+		tok.offset = m.post.YOffset
+	default:
+		return tok, false
+	}
+	return tok, true
+}
+
+// restoreView switches to the view of tok, welcoming the visitor back.
+func (m model) restoreView(tok viewToken) (model, tea.Cmd) {
+	// The menu may have changed since if the links were reloaded.
+	if tok.choice <= m.lastChoice() {
+		m.Choice = tok.choice
+	}
+	m.visitorName = tok.name
+	m.state = stateMenu
+	m.typing = false
+
+	var cmd tea.Cmd
+	switch tok.state {
+	case stateLink:
+		if _, url := m.choiceLink(m.Choice); url != "" {
+			m.state = stateLink
+		}
+	case stateContact:
+		m = m.showContact()
+	case stateQR:
+		m = m.showQR()
+	case stateResume:
+		m = m.showResume()
+		m.resume.SetYOffset(tok.offset)
+	case stateChangelog:
+		m = m.showChangelog()
+		m.changelog.SetYOffset(tok.offset)
+	case stateBlog, statePost:
+		m = m.showBlog()
+		if tok.state == statePost && tok.post < len(m.posts) {
+			m.blogPages.selected = tok.post
+			m = m.paginateBlog().showPost()
+			m.post.SetYOffset(tok.offset)
+		}
+	case stateOnline, stateStats:
+		m.state = tok.state
+		cmd = onlineTick()
+	case stateProjects:
+		m, cmd = m.showProjects()
+	case stateSkills:
+		m, cmd = m.showSkills()
+	case stateShare:
+		m = m.showShare()
+	}
+	m = m.scrollMenu()
+	m, status := m.setStatusFor("Welcome back, picking up where you left off.", welcomeBackTimeout)
+	return m, tea.Batch(cmd, status)
+}
+
+// programExited saves the view the visitor left the card in and logs what
+// they did, once the program of session s stopped with the model m.
+func (a *app) programExited(s ssh.Session, m tea.Model) {
+	mm, ok := m.(model)
+	if !ok {
+		return
+	}
+	a.logSummary(s, mm)
+	if mm.goodbye == "" {
+		if tok, ok := mm.viewToken(); ok {
+			a.views.put(mm.fingerprint, tok)
+		}
+	}
+}
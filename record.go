@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// recordBuffer is how many events a recording holds before new ones are
+// dropped, sessions never wait for the file to be written.
+const recordBuffer = 1024
+
+// osc52 starts the clipboard writes of copyToClipboard.
+var osc52 = []byte("\x1b]52;")
+
+// castEvent is an event of an asciinema v2 recording: "o" for output, "r"
+// for a resize to "WxH".
+type castEvent struct {
+	at   time.Duration
+	kind string
+	data string
+}
+
+// castRecorder writes the output of a session to an asciinema v2 cast file.
+// Events are written by a goroutine of their own, and dropped if it can't
+// keep up.
+type castRecorder struct {
+	path   string
+	start  time.Time
+	events chan castEvent
+	done   chan struct{}
+
+	mu       sync.Mutex
+	redactor oscRedactor
+	width    int
+	height   int
+	dropped  int
+	closed   bool
+}
+
+// newCastRecorder creates the cast file at path, starting with the header
+// for a terminal of the size of pty.
+func newCastRecorder(path string, pty ssh.Pty) (*castRecorder, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("create recording: %w", err)
+	}
+	r := &castRecorder{
+		path:   path,
+		start:  time.Now(),
+		events: make(chan castEvent, recordBuffer),
+		done:   make(chan struct{}),
+		width:  pty.Window.Width,
+		height: pty.Window.Height,
+	}
+	header, err := json.Marshal(map[string]any{
+		"version":   2,
+		"width":     r.width,
+		"height":    r.height,
+		"timestamp": r.start.Unix(),
+		"env":       map[string]string{"TERM": pty.Term},
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	w.Write(append(header, '\n'))
+	go r.write(f, w)
+	return r, nil
+}
+
+// write appends the events to the file until the recording is closed.
+func (r *castRecorder) write(f *os.File, w *bufio.Writer) {
+	defer close(r.done)
+	for e := range r.events {
+		line, err := json.Marshal([]any{e.at.Seconds(), e.kind, e.data})
+		if err == nil {
+			_, err = w.Write(append(line, '\n'))
+		}
+		if err != nil {
+			log.Error("Could not write recording", "path", r.path, "error", err)
+			break
+		}
+		// Flush when caught up, so a crash loses as little as possible.
+		if len(r.events) == 0 {
+			w.Flush()
+		}
+	}
+	// Keep draining so close doesn't wait on a failed recording.
+	for range r.events {
+	}
+	if err := w.Flush(); err != nil {
+		log.Error("Could not write recording", "path", r.path, "error", err)
+	}
+	f.Close()
+}
+
+// add queues an event, or drops it if the queue is full. r.mu must be held.
+func (r *castRecorder) add(kind, data string) {
+	if r.closed || data == "" {
+		return
+	}
+	select {
+	case r.events <- castEvent{time.Since(r.start), kind, data}:
+	default:
+		r.dropped++
+	}
+}
+
+// output records p written to the client, leaving out clipboard writes.
+func (r *castRecorder) output(p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.add("o", string(r.redactor.redact(p)))
+}
+
+// resize records the terminal being resized to w.
+func (r *castRecorder) resize(w ssh.Window) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if w.Width == r.width && w.Height == r.height {
+		return
+	}
+	r.width, r.height = w.Width, w.Height
+	r.add("r", fmt.Sprintf("%dx%d", w.Width, w.Height))
+}
+
+// close stops recording and waits for the events queued to be written. It
+// returns how many events were dropped.
+func (r *castRecorder) close() int {
+	r.mu.Lock()
+	r.closed = true
+	close(r.events)
+	dropped := r.dropped
+	r.mu.Unlock()
+	<-r.done
+	return dropped
+}
+
+// oscRedactor removes OSC 52 sequences from output, so what visitors copy
+// doesn't end up in recordings. It's fed the output in chunks, holding back
+// what could be the start of a sequence or of a rune split across chunks.
+type oscRedactor struct {
+	pending []byte
+	inside  bool // in a sequence, up to its BEL or ST terminator
+	esc     bool // in a sequence, and the last byte was ESC
+}
+
+func (r *oscRedactor) redact(p []byte) []byte {
+	buf := append(r.pending, p...)
+	r.pending = nil
+	var out []byte
+	for i := 0; i < len(buf); {
+		if r.inside {
+			c := buf[i]
+			i++
+			if c == '\a' || (r.esc && c == '\\') {
+				r.inside = false
+			}
+			r.esc = c == 0x1b
+			continue
+		}
+		j := bytes.Index(buf[i:], osc52)
+		if j < 0 {
+			rest := buf[i:]
+			k := partialPrefix(rest, osc52)
+			out = append(out, rest[:len(rest)-k]...)
+			r.pending = append(r.pending, rest[len(rest)-k:]...)
+			break
+		}
+		out = append(out, buf[i:i+j]...)
+		i += j + len(osc52)
+		r.inside, r.esc = true, false
+	}
+	if len(r.pending) == 0 {
+		for k := 1; k <= min(utf8.UTFMax-1, len(out)); k++ {
+			if tail := out[len(out)-k:]; utf8.RuneStart(tail[0]) {
+				if !utf8.FullRune(tail) {
+					r.pending = append(r.pending, tail...)
+					out = out[:len(out)-k]
+				}
+				break
+			}
+		}
+	}
+	return out
+}
+
+// partialPrefix returns the length of the longest end of b which is the
+// start of prefix.
+func partialPrefix(b, prefix []byte) int {
+	for k := min(len(prefix)-1, len(b)); k > 0; k-- {
+		if bytes.HasSuffix(b, prefix[:k]) {
+			return k
+		}
+	}
+	return 0
+}
+
+// recordedSession records what's written to the session and its window
+// changes.
+type recordedSession struct {
+	ssh.Session
+	rec     *castRecorder
+	once    sync.Once
+	windows <-chan ssh.Window
+}
+
+func (s *recordedSession) Write(p []byte) (int, error) {
+	n, err := s.Session.Write(p)
+	if n > 0 {
+		s.rec.output(p[:n])
+	}
+	return n, err
+}
+
+// Pty returns the window changes of the session, recording them on the way.
+func (s *recordedSession) Pty() (ssh.Pty, <-chan ssh.Window, bool) {
+	pty, windows, ok := s.Session.Pty()
+	if !ok {
+		return pty, windows, ok
+	}
+	s.once.Do(func() {
+		forward := make(chan ssh.Window, 1)
+		s.windows = forward
+		go func() {
+			for w := range windows {
+				s.rec.resize(w)
+				select {
+				case forward <- w:
+				case <-s.Context().Done():
+					return
+				}
+			}
+		}()
+	})
+	return pty, s.windows, ok
+}
+
+// recordMiddleware records every interactive session to an asciinema cast
+// file in dir, named after when it started and its session ID. It does
+// nothing if dir is empty.
+func recordMiddleware(dir string) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			pty, _, isPty := s.Pty()
+			if dir == "" || !isPty {
+				next(s)
+				return
+			}
+			name := fmt.Sprintf("%s-%s.cast", time.Now().UTC().Format("20060102T150405Z"), sessionID(s))
+			rec, err := newCastRecorder(filepath.Join(dir, name), pty)
+			if err != nil {
+				log.Error("Could not record session", "session", sessionID(s), "error", err)
+				next(s)
+				return
+			}
+			defer func() {
+				if dropped := rec.close(); dropped > 0 {
+					log.Warn("Recording is missing events", "path", rec.path, "dropped", dropped)
+				}
+			}()
+			next(&recordedSession{Session: s, rec: rec})
+		}
+	}
+}
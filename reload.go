@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+)
+
+// configStore holds the config in effect, as the reloader updates it.
+type configStore struct {
+	mu  sync.RWMutex
+	cfg Config
+}
+
+func (s *configStore) get() Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg
+}
+
+func (s *configStore) set(cfg Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+}
+
+// reloader applies the settings which can change while the server is
+// running, on SIGHUP. Sessions already connected keep going, new ones pick up
+// the changes. cfg is the config in effect, shared through config once a
+// reload is done.
+type reloader struct {
+	cfg      Config
+	config   *configStore
+	posts    []post
+	skills   []skill
+	changes  string // markdown of the changelog
+	links    []link
+	deny     *denylist
+	limiter  *rateLimiter
+	items    *itemStore
+	taglines *taglineStore
+	cmds     commands
+	files    *sftpFS
+	banner   atomic.Value // string
+
+	maintenance *maintenanceMode
+	online      *sessionRegistry
+	accessLog   *accessLog
+}
+
+// readBanner returns the SSH banner set in cfg, read from BannerFile if it's
+// set.
+func readBanner(cfg Config) (string, error) {
+	banner := cfg.Banner
+	if cfg.BannerFile != "" {
+		data, err := os.ReadFile(cfg.BannerFile)
+		if err != nil {
+			return "", fmt.Errorf("read banner: %w", err)
+		}
+		banner = string(data)
+	}
+	if banner = strings.TrimRight(banner, "\n"); banner != "" {
+		banner += "\n"
+	}
+	return banner, nil
+}
+
+// bannerHandler returns the current banner, clients don't show an empty one.
+func (r *reloader) bannerHandler(ssh.Context) string {
+	return r.banner.Load().(string)
+}
+
+// reload reads the config again and applies what changed. Settings which
+// can't change without a restart are left as they are.
+func (r *reloader) reload() {
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Error("Could not reload config", "error", err)
+		return
+	}
+	if !slices.Equal(cfg.Listen, r.cfg.Listen) || cfg.HostKeyDir != r.cfg.HostKeyDir || cfg.Denylist != r.cfg.Denylist || cfg.AccessLog != r.cfg.AccessLog {
+		log.Warn("Listen addresses, host keys and the denylist and access log paths only change on restart")
+	}
+
+	var changed []string
+	if r.deny != nil {
+		if err := r.deny.reload(); err != nil {
+			log.Error("Could not reload denylist", "error", err)
+		}
+	}
+	// The access log may have been moved away by logrotate.
+	if r.accessLog != nil {
+		if err := r.accessLog.reopen(); err != nil {
+			log.Error("Could not reopen access log", "error", err)
+		}
+	}
+	if cfg.RateLimit != r.cfg.RateLimit {
+		r.limiter.setLimit(cfg.RateLimit)
+		r.cfg.RateLimit = cfg.RateLimit
+		changed = append(changed, "rate limit")
+	}
+	// Settings are only recorded once they're applied, so the config in
+	// effect stays accurate and a failed one is tried again on next reload.
+	if banner, err := readBanner(cfg); err != nil {
+		log.Error("Could not reload banner", "error", err)
+	} else {
+		if banner != r.banner.Load() {
+			r.banner.Store(banner)
+			changed = append(changed, "banner")
+		}
+		r.cfg.Banner, r.cfg.BannerFile = cfg.Banner, cfg.BannerFile
+	}
+	if links, err := loadLinks(cfg.LinksFile); err != nil {
+		log.Error("Could not reload links", "error", err)
+	} else {
+		if !reflect.DeepEqual(links, r.links) || cfg.ContactEmail != r.cfg.ContactEmail {
+			r.links = links
+			r.items.set(newMenuItems(cfg.ContactEmail, r.posts, r.skills, r.changes, links))
+			r.files.refresh(r.cmds)
+			changed = append(changed, "links")
+		}
+		r.cfg.LinksFile, r.cfg.ContactEmail = cfg.LinksFile, cfg.ContactEmail
+	}
+	if taglines, err := loadTaglines(cfg.TaglinesFile); err != nil {
+		log.Error("Could not reload taglines", "error", err)
+	} else {
+		if !slices.Equal(taglines, r.taglines.get()) {
+			r.taglines.set(taglines)
+			changed = append(changed, "taglines")
+		}
+		r.cfg.TaglinesFile = cfg.TaglinesFile
+	}
+	if r.reloadMaintenance(cfg) {
+		changed = append(changed, "maintenance")
+	}
+	r.config.set(r.cfg)
+	log.Info("Reloaded config", "changed", changed)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/glamour"
+	"github.com/muesli/termenv"
+)
+
+//go:embed resume.md
+var resumeMarkdown string
+
+// showResume renders the bundled resume into a viewport sized to the window
+// and switches to the resume view.
+func (m model) showResume() model {
+	width := max(m.Width-4, 20)
+	m.resume = viewport.New(width, max(m.bodyHeight()-6, 1))
+	m.resume.SetContent(m.renderMarkdown(resumeMarkdown, width))
+	m.state = stateResume
+	return m
+}
+
+// renderMarkdown renders md with glamour wrapped at width, using a style
+// matching the current theme and the color profile of the client terminal.
+// The raw markdown is returned if rendering fails.
+func (m model) renderMarkdown(md string, width int) string {
+	r, err := glamour.NewTermRenderer(
+		glamour.WithStandardStyle(m.glamourStyle()),
+		glamour.WithColorProfile(m.renderer.ColorProfile()),
+		glamour.WithWordWrap(width),
+	)
+	if err != nil {
+		return md
+	}
+	out, err := r.Render(md)
+	if err != nil {
+		return md
+	}
+	return out
+}
+
+// glamourStyle is the style markdown is rendered with, plain text in plain
+// mode and on terminals without colors, where glamour would still make text
+// bold or italic.
+func (m model) glamourStyle() string {
+	if m.plain || m.renderer.ColorProfile() == termenv.Ascii {
+		return "ascii"
+	}
+	return themes[m.theme].glamour
+}
+
+func (m model) resumeView() string {
+	title := m.aboutNameStyle.Render("Resume / CV")
+	tpl := m.hintLine()
+
+	s := fmt.Sprintf("%s\n\n%s\n\n%s", title, m.resume.View(), tpl)
+	return m.mainStyle.Render("\n" + s + "\n")
+}
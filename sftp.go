@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/pkg/sftp"
+)
+
+// sftpFS is the read-only virtual filesystem served over SFTP, a flat
+// directory of files kept in memory.
+type sftpFS struct {
+	mu      sync.RWMutex
+	modTime time.Time
+	files   map[string][]byte
+}
+
+// newSFTPFS builds the files served over SFTP from the output of cmds. The
+// resume PDF is read from resumePDF and left out if it doesn't exist.
+func newSFTPFS(resumePDF string, cmds commands) (*sftpFS, error) {
+	f := &sftpFS{files: make(map[string][]byte)}
+	f.refresh(cmds)
+
+	if resumePDF == "" {
+		return f, nil
+	}
+	pdf, err := os.ReadFile(resumePDF)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Warn("Resume PDF not found, it won't be served over SFTP", "path", resumePDF)
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read resume pdf: %w", err)
+	}
+	f.files["resume.pdf"] = pdf
+	return f, nil
+}
+
+// refresh renders the text files again from the output of cmds, after the
+// links changed.
+func (f *sftpFS) refresh(cmds commands) {
+	var about, links bytes.Buffer
+	cmds["about"](&about)
+	cmds["links"](&links)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.modTime = time.Now()
+	f.files["about.txt"] = about.Bytes()
+	f.files["links.txt"] = links.Bytes()
+}
+
+// handler serves the filesystem on an sftp subsystem session.
+func (f *sftpFS) handler(s ssh.Session) {
+	h := sftp.Handlers{FileGet: f, FilePut: f, FileCmd: f, FileList: f}
+	srv := sftp.NewRequestServer(s, h)
+	if err := srv.Serve(); err != nil && !errors.Is(err, io.EOF) {
+		log.Error("SFTP server error", "error", err)
+	}
+	_ = srv.Close()
+}
+
+// lookup returns the file at p. Requests are cleaned and rooted by the sftp
+// server, so anything other than a file right below "/" doesn't exist.
+func (f *sftpFS) lookup(p string) ([]byte, string, bool) {
+	dir, name := path.Split(p)
+	if dir != "/" {
+		return nil, "", false
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	data, ok := f.files[name]
+	return data, name, ok
+}
+
+func (f *sftpFS) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	data, _, ok := f.lookup(r.Filepath)
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return bytes.NewReader(data), nil
+}
+
+func (f *sftpFS) Filewrite(*sftp.Request) (io.WriterAt, error) {
+	return nil, sftp.ErrSSHFxPermissionDenied
+}
+
+func (f *sftpFS) Filecmd(*sftp.Request) error {
+	return sftp.ErrSSHFxPermissionDenied
+}
+
+func (f *sftpFS) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	switch r.Method {
+	case "List":
+		if r.Filepath != "/" {
+			return nil, os.ErrNotExist
+		}
+		f.mu.RLock()
+		names := make([]string, 0, len(f.files))
+		for name := range f.files {
+			names = append(names, name)
+		}
+		f.mu.RUnlock()
+		sort.Strings(names)
+		infos := make(fileInfos, 0, len(names))
+		for _, name := range names {
+			infos = append(infos, f.stat(name))
+		}
+		return infos, nil
+	case "Stat", "Lstat":
+		if r.Filepath == "/" {
+			f.mu.RLock()
+			defer f.mu.RUnlock()
+			return fileInfos{fileInfo{name: "/", dir: true, modTime: f.modTime}}, nil
+		}
+		if _, name, ok := f.lookup(r.Filepath); ok {
+			return fileInfos{f.stat(name)}, nil
+		}
+		return nil, os.ErrNotExist
+	}
+	return nil, sftp.ErrSSHFxOpUnsupported
+}
+
+func (f *sftpFS) stat(name string) fileInfo {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return fileInfo{name: name, size: int64(len(f.files[name])), modTime: f.modTime}
+}
+
+type fileInfos []os.FileInfo
+
+func (l fileInfos) ListAt(dst []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+	n := copy(dst, l[offset:])
+	if n < len(dst) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// fileInfo describes a read-only file of the sftpFS.
+type fileInfo struct {
+	name    string
+	size    int64
+	dir     bool
+	modTime time.Time
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) ModTime() time.Time { return i.modTime }
+func (i fileInfo) IsDir() bool        { return i.dir }
+func (i fileInfo) Sys() any           { return nil }
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"net"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const defaultPublicHost = cardTitle
+
+// sshCommand returns the command which connects to the card at host,
+// leaving out the port when it's the default one.
+func sshCommand(host string) string {
+	h, port, err := net.SplitHostPort(host)
+	if err != nil || port == "22" {
+		if err == nil {
+			host = h
+		}
+		return "ssh " + host
+	}
+	return fmt.Sprintf("ssh -p %s %s", port, h)
+}
+
+// parsePublicHost checks the host the card is advertised at, with an
+// optional port.
+func parsePublicHost(host string) error {
+	if _, port, err := net.SplitHostPort(host); err == nil {
+		if _, err := net.LookupPort("tcp", port); err != nil {
+			return fmt.Errorf("bad port %q", port)
+		}
+	}
+	return nil
+}
+
+func (m model) showShare() model {
+	m.state = stateShare
+	return m
+}
+
+// updateShare copies the command, or goes back to the menu.
+func (m model) updateShare(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "c":
+		if m.clipboard == nil {
+			return m, nil
+		}
+		m, tick := m.setStatus("Copied!")
+		return m, tea.Batch(copyToClipboard(m.clipboard, sshCommand(m.publicHost)), tick)
+	case "esc", "backspace", "h", "left":
+		m.state = stateMenu
+	}
+	return m, nil
+}
+
+// shareView shows how to reach the card, for visitors to pass it on.
+func (m model) shareView() string {
+	title := m.aboutNameStyle.Render("Share this card")
+	blurb := m.aboutStyle.Render("Know someone who'd like it? All they need is a terminal:")
+	host, port, err := net.SplitHostPort(m.publicHost)
+	if err != nil {
+		host, port = m.publicHost, "22"
+	}
+	box := m.helpStyle.Render(fmt.Sprintf("%s\n\n%s",
+		m.linkStyle.Render(sshCommand(m.publicHost)),
+		m.subtleStyle.Render(fmt.Sprintf("host %s, port %s", host, port)),
+	))
+	tpl := m.hintLine()
+
+	s := fmt.Sprintf("%s\n\n%s\n\n%s\n\n%s", title, blurb, box, tpl)
+	return m.mainStyle.Render("\n" + s + "\n\n")
+}
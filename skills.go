@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+const (
+	// The bars fill in skillsFrames frames of skillsInterval.
+	skillsFrames   = 20
+	skillsInterval = 30 * time.Millisecond
+	skillsMaxBar   = 40
+)
+
+// skill is a language with how well I know it, as listed in the skills file.
+type skill struct {
+	Name  string  `json:"name"`
+	Level float64 `json:"level"` // from 0 to 1
+}
+
+// loadSkills reads the skills from the JSON file at path. There are none if
+// path is empty, which leaves the skills view out of the menu.
+func loadSkills(path string) ([]skill, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read skills: %w", err)
+	}
+	var skills []skill
+	if err := json.Unmarshal(data, &skills); err != nil {
+		return nil, fmt.Errorf("parse skills %s: %w", path, err)
+	}
+	for i, s := range skills {
+		if s.Name == "" {
+			return nil, fmt.Errorf("parse skills %s: skill %d has no name", path, i+1)
+		}
+		if s.Level < 0 || s.Level > 1 {
+			return nil, fmt.Errorf("parse skills %s: level %g of %s isn't between 0 and 1", path, s.Level, s.Name)
+		}
+	}
+	if len(skills) == 0 {
+		return nil, fmt.Errorf("parse skills %s: no skills", path)
+	}
+	return skills, nil
+}
+
+// skillsTickMsg moves the filling of the bars on, for the animation with the
+// same id only so reopening the view doesn't speed it up.
+type skillsTickMsg struct{ id int }
+
+func skillsTick(id int) tea.Cmd {
+	return tea.Tick(skillsInterval, func(time.Time) tea.Msg {
+		return skillsTickMsg{id}
+	})
+}
+
+// showSkills switches to the skills view, filling the bars unless the
+// animations are off.
+func (m model) showSkills() (model, tea.Cmd) {
+	m.state = stateSkills
+	m.skillsAnim++
+	if !m.animateCaret || m.plain {
+		m.skillsFrame = skillsFrames
+		return m, nil
+	}
+	m.skillsFrame = 0
+	return m, skillsTick(m.skillsAnim)
+}
+
+// updateSkillsTick draws the next frame of the bars filling.
+func (m model) updateSkillsTick(msg skillsTickMsg) (model, tea.Cmd) {
+	if msg.id != m.skillsAnim || m.state != stateSkills || m.skillsFrame >= skillsFrames {
+		return m, nil
+	}
+	m.skillsFrame++
+	if m.skillsFrame == skillsFrames {
+		return m, nil
+	}
+	return m, skillsTick(m.skillsAnim)
+}
+
+// updateSkills goes back to the menu.
+func (m model) updateSkills(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "backspace", "h", "left":
+		m.state = stateMenu
+	}
+	return m, nil
+}
+
+func (m model) skillsView() string {
+	title := m.aboutNameStyle.Render("Skills")
+	tpl := m.hintLine()
+
+	width := 0
+	for _, s := range m.skills {
+		width = max(width, lipgloss.Width(s.Name))
+	}
+	// Ease out, the bars slowing down as they fill.
+	t := float64(m.skillsFrame) / skillsFrames
+	filled := 1 - (1-t)*(1-t)*(1-t)
+
+	profile := m.renderer.ColorProfile()
+	bar := progress.New(
+		progress.WithWidth(min(m.Width-width-6, skillsMaxBar)),
+		progress.WithoutPercentage(),
+		progress.WithColorProfile(profile),
+		progress.WithSolidFill(profileColor(themes[m.theme].accent, profile)),
+	)
+	bar.Full = []rune(m.glyphs.block)[0]
+	bar.Empty = []rune(m.glyphs.shade)[0]
+	bar.EmptyColor = profileColor(themes[m.theme].dim, profile)
+
+	var b strings.Builder
+	for i, s := range m.skills {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(m.aboutStyle.Render(padRight(s.Name, width)) + "  " + bar.ViewAs(s.Level*filled))
+	}
+
+	s := fmt.Sprintf("%s\n\n%s\n\n%s", title, b.String(), tpl)
+	return m.mainStyle.Render("\n" + s + "\n\n")
+}
+
+// profileColor returns the variant of c for the color profile p.
+func profileColor(c lipgloss.CompleteColor, p termenv.Profile) string {
+	switch p {
+	case termenv.TrueColor:
+		return c.TrueColor
+	case termenv.ANSI256:
+		return c.ANSI256
+	}
+	return c.ANSI
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSkills(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    int
+		wantErr bool
+	}{
+		{"valid", `[{"name":"Go","level":0.9},{"name":"Kotlin","level":1}]`, 2, false},
+		{"no name", `[{"level":0.5}]`, 0, true},
+		{"level past 1", `[{"name":"Go","level":1.5}]`, 0, true},
+		{"empty", `[]`, 0, true},
+		{"not json", `Go 90%`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "skills.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			skills, err := loadSkills(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %t", err, tt.wantErr)
+			}
+			if len(skills) != tt.want {
+				t.Errorf("loaded %d skills, want %d", len(skills), tt.want)
+			}
+		})
+	}
+
+	if skills, err := loadSkills(""); err != nil || skills != nil {
+		t.Errorf("loadSkills without a file = %v, %v, want none", skills, err)
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	snakeInterval = 120 * time.Millisecond
+	// Cells are two columns wide, so the board looks roughly square.
+	snakeCellWidth = 2
+	snakeMaxCols   = 30
+	snakeMaxRows   = 20
+)
+
+// konamiCode launches the snake game from the menu, as does "s".
+var konamiCode = []string{"up", "up", "down", "down", "left", "right", "left", "right", "b", "a"}
+
+type point struct{ x, y int }
+
+// snakeTickMsg moves the snake of the game it was scheduled by.
+type snakeTickMsg struct{ id int }
+
+// snakeModel is a minimal snake game, hidden in the menu.
+type snakeModel struct {
+	id         int
+	cols, rows int
+	body       []point // head first
+	dir, next  point
+	food       point
+	score      int
+	over       bool
+	segment    string // a cell of the snake
+
+	snakeStyle lipgloss.Style
+	foodStyle  lipgloss.Style
+	boardStyle lipgloss.Style
+	textStyle  lipgloss.Style
+}
+
+// newSnakeModel starts a game on a board fitting within width and height, id
+// tells its ticks apart from the ones of a previous game. The snake is drawn
+// with block.
+func newSnakeModel(id, width, height int, block string, snakeStyle, foodStyle, boardStyle, textStyle lipgloss.Style) snakeModel {
+	g := snakeModel{
+		id:         id,
+		cols:       max(min((width-2)/snakeCellWidth, snakeMaxCols), 5),
+		rows:       max(min(height-2, snakeMaxRows), 5),
+		dir:        point{1, 0},
+		next:       point{1, 0},
+		segment:    strings.Repeat(block, snakeCellWidth),
+		snakeStyle: snakeStyle,
+		foodStyle:  foodStyle,
+		boardStyle: boardStyle,
+		textStyle:  textStyle,
+	}
+	head := point{g.cols / 2, g.rows / 2}
+	g.body = []point{head, {head.x - 1, head.y}, {head.x - 2, head.y}}
+	g.placeFood()
+	return g
+}
+
+func (g snakeModel) Init() tea.Cmd {
+	return g.tick()
+}
+
+func (g snakeModel) tick() tea.Cmd {
+	id := g.id
+	return tea.Tick(snakeInterval, func(time.Time) tea.Msg {
+		return snakeTickMsg{id}
+	})
+}
+
+func (g snakeModel) Update(msg tea.Msg) (snakeModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		var dir point
+		switch msg.String() {
+		case "up", "k", "w":
+			dir = point{0, -1}
+		case "down", "j", "s":
+			dir = point{0, 1}
+		case "left", "h", "a":
+			dir = point{-1, 0}
+		case "right", "l", "d":
+			dir = point{1, 0}
+		default:
+			return g, nil
+		}
+		// The snake can't turn back on itself.
+		if dir.x != -g.dir.x || dir.y != -g.dir.y {
+			g.next = dir
+		}
+	case snakeTickMsg:
+		if msg.id != g.id || g.over {
+			return g, nil
+		}
+		g = g.step()
+		if g.over {
+			return g, nil
+		}
+		return g, g.tick()
+	}
+	return g, nil
+}
+
+// step moves the snake one cell, growing it when it eats and ending the game
+// when it hits a wall or itself.
+func (g snakeModel) step() snakeModel {
+	g.dir = g.next
+	head := point{g.body[0].x + g.dir.x, g.body[0].y + g.dir.y}
+	if head.x < 0 || head.y < 0 || head.x >= g.cols || head.y >= g.rows {
+		g.over = true
+		return g
+	}
+	eats := head == g.food
+	body := g.body
+	if !eats {
+		body = body[:len(body)-1]
+	}
+	for _, p := range body {
+		if p == head {
+			g.over = true
+			return g
+		}
+	}
+	g.body = append([]point{head}, body...)
+	if eats {
+		g.score++
+		g.placeFood()
+	}
+	return g
+}
+
+// placeFood puts the food on a random cell the snake isn't on.
+func (g *snakeModel) placeFood() {
+	free := make([]point, 0, g.cols*g.rows)
+	for y := 0; y < g.rows; y++ {
+		for x := 0; x < g.cols; x++ {
+			if !g.occupies(point{x, y}) {
+				free = append(free, point{x, y})
+			}
+		}
+	}
+	if len(free) == 0 {
+		g.over = true
+		return
+	}
+	g.food = free[rand.IntN(len(free))]
+}
+
+func (g snakeModel) occupies(p point) bool {
+	for _, b := range g.body {
+		if b == p {
+			return true
+		}
+	}
+	return false
+}
+
+func (g snakeModel) View() string {
+	cell := strings.Repeat(" ", snakeCellWidth)
+	var b strings.Builder
+	for y := 0; y < g.rows; y++ {
+		for x := 0; x < g.cols; x++ {
+			switch p := (point{x, y}); {
+			case g.occupies(p):
+				b.WriteString(g.snakeStyle.Render(g.segment))
+			case p == g.food:
+				b.WriteString(g.foodStyle.Render("<>"))
+			default:
+				b.WriteString(cell)
+			}
+		}
+		if y < g.rows-1 {
+			b.WriteString("\n")
+		}
+	}
+	return g.textStyle.Render(fmt.Sprintf("Score: %d", g.score)) + "\n" + g.boardStyle.Render(b.String())
+}
+
+// startSnake launches the game from the menu.
+func (m model) startSnake() (model, tea.Cmd) {
+	m.snake = newSnakeModel(m.snake.id+1, m.Width-4, m.bodyHeight()-6, m.glyphs.block,
+		m.checkboxStyle,
+		m.aboutNameStyle,
+		m.renderer.NewStyle().Border(m.glyphs.border).BorderForeground(m.dimColor),
+		m.subtleStyle,
+	)
+	m.state = stateSnake
+	return m, m.snake.Init()
+}
+
+// updateSnake plays the game, going back to the menu on esc or game over.
+func (m model) updateSnake(msg tea.Msg) (model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "esc" {
+		m.state = stateMenu
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.snake, cmd = m.snake.Update(msg)
+	if m.snake.over {
+		m.state = stateMenu
+		return m.setStatus(fmt.Sprintf("Game over! Score: %d", m.snake.score))
+	}
+	return m, cmd
+}
+
+// konamiProgress returns how much of konamiCode has been typed with key.
+func konamiProgress(typed int, key string) int {
+	if key == konamiCode[typed] {
+		return typed + 1
+	}
+	if key == konamiCode[0] {
+		// "up up up" still counts as the start of the code.
+		if typed >= 2 && konamiCode[typed-1] == "up" {
+			return typed
+		}
+		return 1
+	}
+	return 0
+}
+
+func (m model) snakeView() string {
+	title := m.aboutNameStyle.Render("Snake")
+	tpl := m.hintLine()
+
+	s := fmt.Sprintf("%s\n\n%s\n\n%s", title, m.snake.View(), tpl)
+	return m.mainStyle.Render("\n" + s + "\n\n")
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+const (
+	// historyMinutes is how far back the sparkline of the footer goes.
+	historyMinutes = 30
+	// historyRefresh is how often the sparkline is rendered again.
+	historyRefresh = 10 * time.Second
+)
+
+// connectionHistory counts the connections of every minute over the last
+// historyMinutes, in a ring buffer indexed by minute.
+type connectionHistory struct {
+	mu     sync.Mutex
+	counts [historyMinutes]int
+	last   int64 // minute of the latest bucket, since the epoch
+}
+
+// advance moves the buffer on to minute, clearing the buckets of the minutes
+// skipped since the last connection. h.mu must be held.
+func (h *connectionHistory) advance(minute int64) {
+	gap := minute - h.last
+	if gap <= 0 {
+		return
+	}
+	for i := int64(1); i <= min(gap, historyMinutes); i++ {
+		h.counts[(h.last+i)%historyMinutes] = 0
+	}
+	h.last = minute
+}
+
+// add counts a connection at now.
+func (h *connectionHistory) add(now time.Time) {
+	minute := now.Unix() / 60
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.advance(minute)
+	h.counts[minute%historyMinutes]++
+}
+
+// perMinute returns the connections of every minute up to now, oldest
+// first.
+func (h *connectionHistory) perMinute(now time.Time) []int {
+	minute := now.Unix() / 60
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.advance(minute)
+	counts := make([]int, historyMinutes)
+	for i := range counts {
+		counts[i] = h.counts[(minute+1+int64(i))%historyMinutes]
+	}
+	return counts
+}
+
+// historyMiddleware counts every session in the connection history.
+func historyMiddleware(h *connectionHistory) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			h.add(time.Now())
+			next(s)
+		}
+	}
+}
+
+// sparkline renders counts as bars scaled to the highest of them, blocks
+// going from the lowest bar to the highest. The minutes without connections
+// are at the bottom.
+func sparkline(counts []int, blocks []rune) string {
+	highest := 0
+	for _, c := range counts {
+		highest = max(highest, c)
+	}
+	bars := make([]rune, len(counts))
+	for i, c := range counts {
+		level := 0
+		if c > 0 {
+			// Round up so a single connection is never drawn as none.
+			level = (c*(len(blocks)-1) + highest - 1) / highest
+		}
+		bars[i] = blocks[level]
+	}
+	return string(bars)
+}
+
+type historyTickMsg struct{}
+
+func historyTick() tea.Cmd {
+	return tea.Tick(historyRefresh, func(time.Time) tea.Msg {
+		return historyTickMsg{}
+	})
+}
+
+// historyText renders the connections of the last minutes for the footer.
+func (m model) historyText() string {
+	if m.history == nil {
+		return ""
+	}
+	return m.glyphs.dot + sparkline(m.history.perMinute(time.Now()), m.glyphs.spark)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+const (
+	spotifyRefresh         = 15 * time.Second
+	spotifyTokenEndpoint   = "https://accounts.spotify.com/api/token"
+	spotifyPlayingEndpoint = "https://api.spotify.com/v1/me/player/currently-playing"
+	spotifyMaxField        = 64
+)
+
+type track struct {
+	name   string
+	artist string
+}
+
+// spotifyClient asks Spotify what I'm listening to. The answer is shared by
+// all sessions and refreshed at most every spotifyRefresh.
+type spotifyClient struct {
+	clientID     string
+	clientSecret string
+	refreshToken string
+	client       *http.Client
+
+	// token and expires are only used by the fetch in flight.
+	token   string
+	expires time.Time
+
+	mu       sync.Mutex
+	playing  *track
+	fetched  time.Time
+	failing  bool
+	fetching chan struct{} // closed once the fetch in flight is done
+}
+
+// newSpotifyClient returns nil if any of the credentials is missing, which
+// hides the widget.
+func newSpotifyClient(clientID, clientSecret, refreshToken string) *spotifyClient {
+	if clientID == "" || clientSecret == "" || refreshToken == "" {
+		return nil
+	}
+	return &spotifyClient{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		refreshToken: refreshToken,
+		client:       &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// nowPlaying returns the track playing, or nil if nothing is or Spotify
+// can't be reached. Spotify is asked with ctx, the context of the session
+// asking, so the request is cancelled along with the session.
+func (c *spotifyClient) nowPlaying(ctx context.Context) *track {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Spotify is called without holding c.mu, sessions asking meanwhile
+	// wait for the fetch in flight instead of starting another, and start
+	// their own if it was cancelled.
+	for c.fetching != nil {
+		wait := c.fetching
+		c.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			c.mu.Lock()
+			return nil
+		}
+		c.mu.Lock()
+	}
+	if time.Since(c.fetched) < spotifyRefresh {
+		return c.playing
+	}
+	done := make(chan struct{})
+	c.fetching = done
+	c.mu.Unlock()
+	playing, err := c.fetch(ctx)
+	c.mu.Lock()
+	c.fetching = nil
+	close(done)
+	// The session leaving isn't Spotify failing.
+	if ctx.Err() != nil {
+		return nil
+	}
+	c.fetched = time.Now()
+	// Log failures once rather than on every refresh.
+	if err != nil && !c.failing {
+		log.Warn("Could not fetch Spotify now playing", "error", err)
+	}
+	c.failing = err != nil
+	c.playing = playing
+	return c.playing
+}
+
+// fetch returns the track playing, refreshing the access token when it
+// expired. It's only called by the fetch in flight.
+func (c *spotifyClient) fetch(ctx context.Context) (*track, error) {
+	if time.Now().After(c.expires) {
+		if err := c.refreshAccessToken(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, spotifyPlayingEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		return nil, nil
+	case http.StatusUnauthorized:
+		c.expires = time.Time{}
+		fallthrough
+	default:
+		return nil, fmt.Errorf("spotify api: %s", resp.Status)
+	}
+
+	var playing struct {
+		IsPlaying bool `json:"is_playing"`
+		Item      *struct {
+			Name    string `json:"name"`
+			Artists []struct {
+				Name string `json:"name"`
+			} `json:"artists"`
+		} `json:"item"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&playing); err != nil {
+		return nil, fmt.Errorf("decode spotify now playing: %w", err)
+	}
+	if !playing.IsPlaying || playing.Item == nil {
+		return nil, nil
+	}
+	artists := make([]string, len(playing.Item.Artists))
+	for i, a := range playing.Item.Artists {
+		artists[i] = a.Name
+	}
+	return &track{
+		name:   sanitize(playing.Item.Name, spotifyMaxField),
+		artist: sanitize(strings.Join(artists, ", "), spotifyMaxField),
+	}, nil
+}
+
+// refreshAccessToken trades the refresh token for a new access token. It's
+// only called by the fetch in flight.
+func (c *spotifyClient) refreshAccessToken(ctx context.Context) error {
+	form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {c.refreshToken}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, spotifyTokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.clientID, c.clientSecret)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("spotify token: %s", resp.Status)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("decode spotify token: %w", err)
+	}
+	c.token = token.AccessToken
+	// Refresh a bit early so requests don't race the expiry.
+	c.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return nil
+}
+
+type nowPlayingMsg struct{ track *track }
+
+func loadNowPlaying(ctx context.Context, c *spotifyClient) tea.Cmd {
+	return func() tea.Msg {
+		return nowPlayingMsg{c.nowPlaying(ctx)}
+	}
+}
+
+func nowPlayingTick(ctx context.Context, c *spotifyClient) tea.Cmd {
+	return tea.Tick(spotifyRefresh, func(time.Time) tea.Msg {
+		return nowPlayingMsg{c.nowPlaying(ctx)}
+	})
+}
+
+// nowPlayingText renders the track playing for the footer, or nothing if
+// there's none.
+func (m model) nowPlayingText() string {
+	if m.playing == nil {
+		return ""
+	}
+	g := m.glyphs
+	return g.dot + g.music + " " + m.playing.name + " " + g.dash + " " + m.playing.artist
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSpotifyNowPlayingFetchesOnceWithoutLocking(t *testing.T) {
+	transport := &blockingTransport{
+		body:    `{"is_playing":true,"item":{"name":"Song","artists":[{"name":"Band"}]}}`,
+		started: make(chan struct{}, 5),
+		release: make(chan struct{}),
+	}
+	c := newSpotifyClient("id", "secret", "refresh")
+	c.client = &http.Client{Transport: transport}
+	c.token, c.expires = "token", time.Now().Add(time.Hour)
+
+	var wg sync.WaitGroup
+	results := make([]*track, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.nowPlaying(context.Background())
+		}()
+	}
+	<-transport.started
+	// The lock is free while Spotify is called.
+	c.mu.Lock()
+	c.mu.Unlock()
+	close(transport.release)
+	wg.Wait()
+
+	if n := transport.requests.Load(); n != 1 {
+		t.Errorf("%d requests to Spotify, want 1", n)
+	}
+	for i, playing := range results {
+		if playing == nil || playing.name != "Song" || playing.artist != "Band" {
+			t.Errorf("nowPlaying %d returned %v, want Song by Band", i, playing)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// statsView shows how busy the server is, refreshed every second by the
+// online tick.
+func (m model) statsView() string {
+	title := m.aboutNameStyle.Render("Stats")
+	tpl := m.hintLine()
+
+	stats := []struct{ label, value string }{
+		{"Online now", fmt.Sprint(len(m.online.list()))},
+		{"Visitors", fmt.Sprint(m.visitors.count())},
+		{"Uptime", time.Since(startTime).Truncate(time.Second).String()},
+	}
+	var b strings.Builder
+	for _, s := range stats {
+		fmt.Fprintf(&b, "%s  %s\n", m.checkboxStyle.Render(padRight(s.label, 12)), m.aboutStyle.Render(s.value))
+	}
+
+	s := fmt.Sprintf("%s\n\n%s\n%s", title, b.String(), tpl)
+	return m.mainStyle.Render("\n" + s + "\n\n")
+}
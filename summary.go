@@ -0,0 +1,79 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+)
+
+// viewNames name the views in the session summaries, by viewState.
+var viewNames = [...]string{
+	stateMenu:      "menu",
+	stateLink:      "link",
+	stateQR:        "qr",
+	stateGuestbook: "guestbook",
+	stateOnline:    "online",
+	stateResume:    "resume",
+	stateProjects:  "projects",
+	stateContact:   "contact",
+	stateSnake:     "snake",
+	stateName:      "name",
+	stateAdmin:     "admin",
+	stateBlog:      "blog",
+	statePost:      "post",
+	stateStats:     "stats",
+	stateError:     "error",
+	stateSkills:    "skills",
+	stateShare:     "share",
+	stateChangelog: "changelog",
+}
+
+// sessionSummary is what a visitor did on the card, logged once they leave.
+type sessionSummary struct {
+	start   time.Time
+	keys    int
+	visited uint64 // bit i set once viewState i was shown
+}
+
+// track counts msg if it's a key press, and records the view of m as
+// visited.
+func (s sessionSummary) track(msg tea.Msg, m model) sessionSummary {
+	if _, ok := msg.(tea.KeyMsg); ok {
+		s.keys++
+	}
+	s.visited |= 1 << m.state
+	return s
+}
+
+// views returns the names of the views visited.
+func (s sessionSummary) views() []string {
+	var names []string
+	for state, name := range viewNames {
+		if s.visited&(1<<state) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// logSummary logs what the visitor of session s did on the card, with the
+// final model m.
+func (a *app) logSummary(s ssh.Session, m model) {
+	if !a.cfg.Middleware.Logging || m.summary.start.IsZero() {
+		return
+	}
+	var choice string
+	if m.Choice >= 0 && m.Choice <= m.lastChoice() {
+		choice = m.items[m.Choice].label // Untranslated, to group them.
+	}
+	log.Info("Session summary",
+		"user", s.User(),
+		"ip", m.ip,
+		"duration", time.Since(m.summary.start),
+		"keys", m.summary.keys,
+		"views", m.summary.views(),
+		"choice", choice,
+	)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"sync"
+)
+
+// loadTaglines reads the taglines from the file at path, one per line
+// skipping blank ones. There are none if path is empty.
+func loadTaglines(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read taglines: %w", err)
+	}
+	var taglines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = sanitize(line, aboutMaxWidth); line != "" {
+			taglines = append(taglines, line)
+		}
+	}
+	if len(taglines) == 0 {
+		return nil, fmt.Errorf("parse taglines %s: no taglines", path)
+	}
+	return taglines, nil
+}
+
+// taglineStore holds the taglines, which are replaced when they're
+// reloaded.
+type taglineStore struct {
+	mu       sync.RWMutex
+	taglines []string
+}
+
+func (s *taglineStore) get() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.taglines
+}
+
+func (s *taglineStore) set(taglines []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taglines = taglines
+}
+
+// pick returns one of the taglines at random. Sessions pick theirs once, so
+// it stays the same while they last.
+func (s *taglineStore) pick() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.taglines) == 0 {
+		return ""
+	}
+	return s.taglines[rand.IntN(len(s.taglines))]
+}
+
+// taglineView renders the tagline of the session, wrapped like the about
+// text.
+func (m model) taglineView() string {
+	if m.tagline == "" {
+		return ""
+	}
+	return m.subtleStyle.Copy().Italic(true).Width(m.aboutWidth()).Render(m.tagline)
+}
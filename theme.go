@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/muesli/termenv"
+)
+
+// theme is a color palette the card can be rendered with. Colors are given
+// for every color profile so they degrade gracefully on terminals with fewer
+// colors, instead of relying on the nearest match of a 256 color code.
+type theme struct {
+	name    string
+	accent  lipgloss.CompleteColor
+	text    lipgloss.CompleteColor
+	title   lipgloss.CompleteColor
+	subtle  lipgloss.CompleteColor
+	dot     lipgloss.CompleteColor
+	link    lipgloss.CompleteColor
+	dim     lipgloss.CompleteColor
+	items   []lipgloss.CompleteColor
+	glamour string
+	border  string // overriding SSH_BORDER when set
+}
+
+// color returns a color in TrueColor, ANSI256 and ANSI variants.
+func color(trueColor, ansi256, ansi string) lipgloss.CompleteColor {
+	return lipgloss.CompleteColor{TrueColor: trueColor, ANSI256: ansi256, ANSI: ansi}
+}
+
+var (
+	black = color("#000000", "0", "0")
+	white = color("#ffffff", "15", "15")
+	// red marks errors the same in every theme.
+	red = color("#ff5f5f", "203", "9")
+)
+
+var themes = []theme{
+	{
+		name:   "dark",
+		accent: color("#ff87ff", "213", "13"),
+		text:   color("#949494", "246", "7"),
+		title:  white,
+		subtle: color("#626262", "241", "8"),
+		dot:    color("#303030", "236", "8"),
+		link:   color("#00afff", "39", "12"),
+		dim:    color("#303030", "236", "8"),
+		items: []lipgloss.CompleteColor{
+			color("#ffd787", "222", "11"),
+			color("#ff00ff", "13", "13"),
+			color("#0087ff", "33", "12"),
+			color("#00afff", "39", "14"),
+			color("#87ffaf", "121", "10"),
+		},
+		glamour: "dark",
+	},
+	{
+		name:   "light",
+		accent: color("#d70087", "162", "5"),
+		text:   color("#444444", "238", "0"),
+		title:  color("#080808", "232", "0"),
+		subtle: color("#808080", "244", "8"),
+		dot:    color("#bcbcbc", "250", "7"),
+		link:   color("#005faf", "25", "4"),
+		dim:    color("#dadada", "253", "7"),
+		items: []lipgloss.CompleteColor{
+			color("#af5f00", "130", "3"),
+			color("#870087", "90", "5"),
+			color("#005faf", "25", "4"),
+			color("#0087af", "31", "6"),
+			color("#008700", "28", "2"),
+		},
+		glamour: "light",
+	},
+	{
+		name:   "high contrast",
+		accent: color("#ffff00", "11", "11"),
+		text:   white,
+		title:  white,
+		subtle: white,
+		dot:    white,
+		link:   color("#00ffff", "14", "14"),
+		dim:    color("#808080", "8", "8"),
+		items: []lipgloss.CompleteColor{
+			color("#ffff00", "11", "11"),
+			color("#ff00ff", "13", "13"),
+			color("#00ffff", "14", "14"),
+			color("#5c5cff", "12", "12"),
+			color("#00ff00", "10", "10"),
+		},
+		glamour: "dark",
+	},
+}
+
+// themeIndex returns the index of the theme called name, or -1.
+func themeIndex(name string) int {
+	return themeIndexIn(themes, name)
+}
+
+// withTheme builds every style of the model from the i-th theme.
+func (m model) withTheme(i int) model {
+	t := themes[i]
+	r := m.renderer
+	m.theme = i
+	m.mainStyle = r.NewStyle().MarginLeft(2)
+	m.checkboxStyle = r.NewStyle().Bold(false).Foreground(t.accent)
+	m.aboutStyle = r.NewStyle().Bold(true).Foreground(t.text)
+	m.aboutNameStyle = r.NewStyle().Bold(true).Foreground(t.title)
+	m.subtleStyle = r.NewStyle().Foreground(t.subtle)
+	m.dotStyle = r.NewStyle().Foreground(t.dot).Render(m.glyphs.dot)
+	m.caretStyle = r.NewStyle().Foreground(t.dot)
+	m.linkStyle = r.NewStyle().Bold(true).Underline(true).Foreground(t.link)
+	m.qrStyle = r.NewStyle().Foreground(white).Background(black)
+	m.errorStyle = r.NewStyle().Bold(true).Foreground(red)
+	m.helpStyle = r.NewStyle().Border(m.glyphs.border).BorderForeground(t.accent).Padding(1, 2)
+	m.dimColor = t.dim
+	m.itemStyles = make([]lipgloss.Style, len(t.items))
+	for j, c := range t.items {
+		m.itemStyles[j] = m.subtleStyle.Copy().Foreground(c)
+	}
+	m.banner = m.renderBanner()
+	return m
+}
+
+// matchStyle highlights what matches the filter of a list.
+func (m model) matchStyle() lipgloss.Style {
+	return m.checkboxStyle.Copy().Bold(true).Underline(true)
+}
+
+// themeFile is a theme as written in a theme file, colors left out are the
+// ones of the built-in theme of the same name, or of the first one.
+type themeFile struct {
+	Name    string       `json:"name"`
+	Primary *themeColor  `json:"primary"` // my name and the titles
+	Muted   *themeColor  `json:"muted"`   // the about text
+	Accent  *themeColor  `json:"accent"`  // the selected item
+	Subtle  *themeColor  `json:"subtle"`  // hints and the footer
+	Dot     *themeColor  `json:"dot"`     // separators
+	Link    *themeColor  `json:"link"`
+	Dim     *themeColor  `json:"dim"` // behind the help
+	Items   []themeColor `json:"items"`
+	Glamour string       `json:"glamour"` // "dark" or "light"
+	Border  string       `json:"border"`  // "none", "rounded" or "double"
+}
+
+// loadThemes reads the themes in the JSON file at path. Themes named like a
+// built-in one replace it, the others are added after them.
+func loadThemes(path string) ([]theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read themes: %w", err)
+	}
+	var files []themeFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&files); err != nil {
+		return nil, fmt.Errorf("parse themes %s: %w", path, err)
+	}
+
+	all := append([]theme(nil), themes...)
+	for _, f := range files {
+		if f.Name == "" {
+			return nil, fmt.Errorf("parse themes %s: theme without a name", path)
+		}
+		i := themeIndexIn(all, f.Name)
+		t := all[max(i, 0)]
+		t.name = f.Name
+		for _, c := range []struct {
+			from *themeColor
+			to   *lipgloss.CompleteColor
+		}{
+			{f.Primary, &t.title},
+			{f.Muted, &t.text},
+			{f.Accent, &t.accent},
+			{f.Subtle, &t.subtle},
+			{f.Dot, &t.dot},
+			{f.Link, &t.link},
+			{f.Dim, &t.dim},
+		} {
+			if c.from != nil {
+				*c.to = lipgloss.CompleteColor(*c.from)
+			}
+		}
+		if len(f.Items) > 0 {
+			t.items = make([]lipgloss.CompleteColor, len(f.Items))
+			for j, c := range f.Items {
+				t.items[j] = lipgloss.CompleteColor(c)
+			}
+		}
+		switch f.Glamour {
+		case "":
+		case "dark", "light":
+			t.glamour = f.Glamour
+		default:
+			return nil, fmt.Errorf("parse themes %s: theme %q: invalid glamour %q: must be dark or light", path, f.Name, f.Glamour)
+		}
+		if f.Border != "" {
+			if err := parseBorder(f.Border); err != nil {
+				return nil, fmt.Errorf("parse themes %s: theme %q: %w", path, f.Name, err)
+			}
+			t.border = f.Border
+		}
+		if i >= 0 {
+			all[i] = t
+		} else {
+			all = append(all, t)
+		}
+	}
+	return all, nil
+}
+
+// themeIndexIn returns the index of the theme called name in themes, or -1.
+func themeIndexIn(themes []theme, name string) int {
+	for i, t := range themes {
+		if t.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// themeColor is a color of a theme file, either a "#rrggbb" or 0-255 color
+// code converted for the other color profiles, or an object with one color
+// per profile.
+type themeColor lipgloss.CompleteColor
+
+func (c *themeColor) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		cc, err := parseColor(s)
+		*c = themeColor(cc)
+		return err
+	}
+
+	var v struct {
+		TrueColor string `json:"truecolor"`
+		ANSI256   string `json:"ansi256"`
+		ANSI      string `json:"ansi"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("invalid color %s: must be a string or an object with truecolor, ansi256 and ansi", data)
+	}
+	if _, err := colorful.Hex(v.TrueColor); err != nil {
+		return fmt.Errorf("invalid truecolor %q: must be #rgb or #rrggbb", v.TrueColor)
+	}
+	if n, err := strconv.Atoi(v.ANSI256); err != nil || n < 0 || n > 255 {
+		return fmt.Errorf("invalid ansi256 %q: must be a color code from 0 to 255", v.ANSI256)
+	}
+	if n, err := strconv.Atoi(v.ANSI); err != nil || n < 0 || n > 15 {
+		return fmt.Errorf("invalid ansi %q: must be a color code from 0 to 15", v.ANSI)
+	}
+	*c = themeColor{TrueColor: v.TrueColor, ANSI256: v.ANSI256, ANSI: v.ANSI}
+	return nil
+}
+
+// parseColor parses a "#rrggbb" hex color or a 0-255 color code, deriving the
+// colors for the other profiles.
+func parseColor(s string) (lipgloss.CompleteColor, error) {
+	var c termenv.Color
+	if strings.HasPrefix(s, "#") {
+		if _, err := colorful.Hex(s); err != nil || (len(s) != 4 && len(s) != 7) {
+			return lipgloss.CompleteColor{}, fmt.Errorf("invalid color %q: must be #rgb, #rrggbb or a color code from 0 to 255", s)
+		}
+		c = termenv.RGBColor(s)
+	} else {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > 255 {
+			return lipgloss.CompleteColor{}, fmt.Errorf("invalid color %q: must be #rgb, #rrggbb or a color code from 0 to 255", s)
+		}
+		c = termenv.ANSI256Color(n)
+		if n < 16 {
+			c = termenv.ANSIColor(n)
+		}
+	}
+	return lipgloss.CompleteColor{
+		TrueColor: termenv.ConvertToRGB(c).Hex(),
+		ANSI256:   colorCode(termenv.ANSI256.Convert(c)),
+		ANSI:      colorCode(termenv.ANSI.Convert(c)),
+	}, nil
+}
+
+// colorCode returns the code of a 256 or 16 colors termenv color.
+func colorCode(c termenv.Color) string {
+	switch c := c.(type) {
+	case termenv.ANSI256Color:
+		return strconv.Itoa(int(c))
+	case termenv.ANSIColor:
+		return strconv.Itoa(int(c))
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// choose selects the menu item with the given label.
+func choose(t *testing.T, m model, label string) model {
+	t.Helper()
+	for i, item := range m.items {
+		if item.label == label {
+			m.Choice = i
+			return m
+		}
+	}
+	t.Fatalf("no %s in the menu", label)
+	return m
+}
+
+// TestViewAsciiHasNoEscapes renders every view in every theme without colors
+// and checks none of them sends an escape sequence.
+func TestViewAsciiHasNoEscapes(t *testing.T) {
+	views := map[viewState]func(*testing.T, model) model{
+		stateMenu: func(t *testing.T, m model) model { return m },
+		stateLink: func(t *testing.T, m model) model {
+			m, _ = press(choose(t, m, "GitHub"), "enter")
+			return m
+		},
+		stateQR: func(t *testing.T, m model) model {
+			m, _ = press(choose(t, m, "GitHub"), "r")
+			return m
+		},
+		stateGuestbook: func(t *testing.T, m model) model {
+			m.guestbook = newGuestbookModel(m.book, "127.0.0.1", "visitor", m.aboutNameStyle, m.aboutStyle, m.subtleStyle)
+			m.state = stateGuestbook
+			return m
+		},
+		stateOnline: func(t *testing.T, m model) model {
+			m, _ = press(m, "w")
+			return m
+		},
+		stateResume: func(t *testing.T, m model) model {
+			m, _ = press(choose(t, m, "Resume / CV"), "enter")
+			return m
+		},
+		stateProjects: func(t *testing.T, m model) model {
+			m.state = stateProjects
+			m.projects = &projectsMsg{repos: []repo{{Name: "card", Description: "This card", Stars: 3}}}
+			return m.paginateProjects()
+		},
+		stateContact: func(t *testing.T, m model) model {
+			m, _ = press(choose(t, m, "Contact"), "enter")
+			return m
+		},
+		stateSnake: func(t *testing.T, m model) model {
+			m, _ = m.startSnake()
+			return m
+		},
+		stateName: func(t *testing.T, m model) model {
+			m.state = stateName
+			m.nameInput = newNameInput()
+			return m
+		},
+		stateAdmin: func(t *testing.T, m model) model {
+			m, _ = m.showAdmin()
+			return m
+		},
+		stateBlog: func(t *testing.T, m model) model {
+			m.posts = []post{{title: "Hello", body: "# Hello\n\nSome *markdown*."}}
+			return m.showBlog()
+		},
+		statePost: func(t *testing.T, m model) model {
+			m.posts = []post{{title: "Hello", body: "# Hello\n\nSome *markdown*."}}
+			return m.showBlog().showPost()
+		},
+		stateStats: func(t *testing.T, m model) model {
+			m, _ = press(m, "S")
+			return m
+		},
+		stateError: func(t *testing.T, m model) model {
+			m.state = stateProjects
+			return m.showError(errorMsg{view: stateProjects, action: "Couldn't load the projects", err: errors.New("offline")})
+		},
+		stateSkills: func(t *testing.T, m model) model {
+			m, _ = press(choose(t, m, "Skills"), "enter")
+			return m
+		},
+		stateShare: func(t *testing.T, m model) model {
+			m, _ = press(m, "i")
+			return m
+		},
+		stateChangelog: func(t *testing.T, m model) model {
+			m, _ = press(choose(t, m, "Changelog"), "enter")
+			return m
+		},
+	}
+	for state := stateMenu; state <= stateChangelog; state++ {
+		if views[state] == nil {
+			t.Errorf("no view for state %d", state)
+		}
+	}
+	for state, show := range views {
+		for theme := range themes {
+			m := show(t, newTestModel(t, 80, 24).withTheme(theme))
+			if m.state != state {
+				t.Fatalf("ended in state %d, want %d", m.state, state)
+			}
+			if view := m.View(); strings.Contains(view, "\x1b") {
+				t.Errorf("view of state %d in the %s theme has escape sequences:\n%q", state, themes[theme].name, view)
+			}
+		}
+	}
+}
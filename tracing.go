@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const serviceName = "ssh-kaustubhpatange.com"
+
+// tracer creates the spans of the card. It does nothing until setupTracing
+// installed an exporter.
+var tracer = otel.Tracer("ssh")
+
+type traceContextKey struct{}
+
+// setupTracing exports spans over OTLP/HTTP to the endpoint configured by the
+// standard OTEL_EXPORTER_OTLP_* variables. Tracing stays a no-op when no
+// endpoint is set. The returned function flushes the pending spans.
+func setupTracing(ctx context.Context) (func(context.Context) error, error) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// tracingMiddleware wraps every session in a span lasting until it
+// disconnects. Its context, derived from the session context, is stored
+// under traceContextKey for the spans of what happens during the session.
+func tracingMiddleware() wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			_, _, isPty := s.Pty()
+			ctx, span := tracer.Start(s.Context(), "ssh.session",
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("ssh.user", s.User()),
+					attribute.String("ssh.command", strings.Join(s.Command(), " ")),
+					attribute.Bool("ssh.pty", isPty),
+					attribute.String("ssh.client", s.Context().ClientVersion()),
+					semconv.ClientAddress(remoteIP(s)),
+				),
+			)
+			defer span.End()
+			s.Context().SetValue(traceContextKey{}, ctx)
+			next(s)
+		}
+	}
+}
+
+// traceContext returns the context of the span of s, or the context of s if
+// it isn't traced.
+func traceContext(s ssh.Session) context.Context {
+	if ctx, ok := s.Context().Value(traceContextKey{}).(context.Context); ok {
+		return ctx
+	}
+	return s.Context()
+}
+
+// traceEvent records something instantaneous happening in the session, like
+// opening a link, as a span of its own.
+func (m model) traceEvent(name string, attrs ...attribute.KeyValue) {
+	_, span := tracer.Start(m.ctx, name, trace.WithAttributes(attrs...))
+	span.End()
+}
+
+// traced runs fn in a span called name, marking the span as failed if fn
+// returns an error.
+func traced(ctx context.Context, name string, fn func() error) {
+	_, span := tracer.Start(ctx, name)
+	defer span.End()
+	if err := fn(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// aboutMaxWidth is the most the about text is wide, a comfortable line
+	// length.
+	aboutMaxWidth      = 80
+	typewriterInterval = 15 * time.Millisecond
+	typewriterStep     = 2 // runes revealed per tick
+)
+
+type typewriterTickMsg struct{}
+
+func typewriterTick() tea.Cmd {
+	return tea.Tick(typewriterInterval, func(time.Time) tea.Msg {
+		return typewriterTickMsg{}
+	})
+}
+
+// updateTypewriter reveals the next runes of the about text, stopping the
+// animation once it's all shown.
+func (m model) updateTypewriter() (model, tea.Cmd) {
+	if !m.typing {
+		return m, nil
+	}
+	m.typed += typewriterStep
+	before, after := m.aboutParts()
+	if m.typed >= utf8.RuneCountInString(before+myName+after) {
+		m.typing = false
+		return m, nil
+	}
+	return m, typewriterTick()
+}
+
+// aboutParts returns the about text around my name, after a hello from the
+// city of the visitor if it's known.
+func (m model) aboutParts() (before, after string) {
+	before, after, _ = strings.Cut(fmt.Sprintf(unwrapParagraphs(m.tr.About), m.greeting(), "\x00"), "\x00")
+	if m.city != "" {
+		before = fmt.Sprintf(m.tr.HelloFrom, m.city) + "\n\n" + before
+	}
+	return before, after
+}
+
+// about renders the about text, only up to the revealed runes while the
+// typewriter animation runs, with the languages after my name in their
+// colors. Its height doesn't change while typing so the menu below stays
+// put.
+func (m model) about() string {
+	before, after := m.aboutParts()
+	style := m.aboutStyle.Copy().Width(m.aboutWidth())
+	full := style.Render(before + m.aboutNameStyle.Render(myName) + colorLanguages(m.aboutStyle, after))
+	if !m.typing {
+		return full
+	}
+
+	n := m.typed
+	before, n = firstRunes(before, n)
+	name, n := firstRunes(myName, n)
+	after, _ = firstRunes(after, n)
+	if name != "" {
+		name = m.aboutNameStyle.Render(name)
+	}
+	return style.Height(lipgloss.Height(full)).Render(before + name + colorLanguages(m.aboutStyle, after))
+}
+
+// aboutWidth is the width the about text is wrapped at, the window width
+// within the margins but no wider than a comfortable line length.
+func (m model) aboutWidth() int {
+	return max(min(m.Width-4, aboutMaxWidth), 20)
+}
+
+// unwrapParagraphs joins the lines of every paragraph of s, so it can be
+// wrapped again at any width.
+func unwrapParagraphs(s string) string {
+	paragraphs := strings.Split(s, "\n\n")
+	for i, p := range paragraphs {
+		lines := strings.Split(p, "\n")
+		for j, l := range lines {
+			lines[j] = strings.TrimSpace(l)
+		}
+		paragraphs[i] = strings.Join(lines, " ")
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// firstRunes returns up to the first n runes of s, and how many of the n
+// runes are left.
+func firstRunes(s string, n int) (string, int) {
+	r := []rune(s)
+	if n >= len(r) {
+		return s, n - len(r)
+	}
+	return string(r[:n]), 0
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+const visitorDebounce = 10 * time.Minute
+
+// visitorCounter counts the visitors of the card, persisted to a file so the
+// total survives restarts. Reconnects from the same IP within the debounce
+// period aren't counted again, and the IPs seen longer ago than that are
+// cleaned up periodically.
+type visitorCounter struct {
+	path     string
+	debounce time.Duration
+
+	mu    sync.Mutex
+	total int64
+	seen  map[string]time.Time
+
+	// saveMu serializes the writes of the total, which happen outside mu so
+	// the visits don't wait for the disk.
+	saveMu sync.Mutex
+}
+
+// loadVisitorCounter reads the total from path, or its backup if it's
+// damaged, starting from zero if the file doesn't exist yet.
+func loadVisitorCounter(path string, debounce time.Duration) (*visitorCounter, error) {
+	c := &visitorCounter{
+		path:     path,
+		debounce: debounce,
+		seen:     make(map[string]time.Time),
+	}
+	err := loadStateFile(path, func(data []byte) error {
+		total, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return err
+		}
+		c.total = total
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("load visitor count: %w", err)
+	}
+	if debounce > 0 {
+		go func() {
+			for range time.Tick(debounce) {
+				c.cleanup()
+			}
+		}()
+	}
+	return c, nil
+}
+
+// visit records a visit from ip.
+func (c *visitorCounter) visit(ip string) {
+	now := time.Now()
+	c.mu.Lock()
+	if t, ok := c.seen[ip]; ok && now.Sub(t) < c.debounce {
+		c.mu.Unlock()
+		return
+	}
+	c.seen[ip] = now
+	c.total++
+	c.mu.Unlock()
+	c.save()
+}
+
+// save writes the total to the file. The total is read once the previous
+// write is done, so the last write always has the latest one.
+func (c *visitorCounter) save() {
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+	if err := writeFileAtomic(c.path, []byte(strconv.FormatInt(c.count(), 10)+"\n"), 0o644); err != nil {
+		log.Error("Could not save visitor count", "error", err)
+	}
+}
+
+// cleanup forgets the IPs seen longer ago than the debounce period.
+func (c *visitorCounter) cleanup() {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for ip, t := range c.seen {
+		if now.Sub(t) >= c.debounce {
+			delete(c.seen, ip)
+		}
+	}
+}
+
+// count returns the total number of visitors.
+func (c *visitorCounter) count() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.total
+}
+
+// visitorMiddleware counts every session as a visit.
+func visitorMiddleware(c *visitorCounter) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			c.visit(remoteIP(s))
+			next(s)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestVisitorCounterSavesLatestTotal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "visitors.count")
+	c, err := loadVisitorCounter(path, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.visit(fmt.Sprintf("10.0.0.%d", i))
+			c.visit(fmt.Sprintf("10.0.0.%d", i))
+		}()
+	}
+	wg.Wait()
+
+	if n := c.count(); n != 20 {
+		t.Errorf("count = %d, want 20", n)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "20" {
+		t.Errorf("saved %s, want 20", got)
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+const (
+	weatherRefresh  = 30 * time.Minute
+	weatherRetry    = 5 * time.Minute
+	weatherEndpoint = "https://api.open-meteo.com/v1/forecast?latitude=%s&longitude=%s&current=temperature_2m,weather_code"
+	geocodeEndpoint = "https://geocoding-api.open-meteo.com/v1/search?count=1&name=%s"
+	weatherMaxCity  = 32
+	weatherTimeout  = 5 * time.Second
+)
+
+// sky is the kind of weather, as drawn by the glyphs.
+type sky int
+
+const (
+	skyClear sky = iota
+	skyCloudy
+	skyFog
+	skyRain
+	skySnow
+	skyStorm
+)
+
+// skyFor groups the WMO weather codes Open-Meteo reports.
+func skyFor(code int) sky {
+	switch {
+	case code <= 1:
+		return skyClear
+	case code <= 3:
+		return skyCloudy
+	case code <= 48:
+		return skyFog
+	case code >= 95:
+		return skyStorm
+	case code >= 71 && code <= 77, code == 85, code == 86:
+		return skySnow
+	}
+	return skyRain
+}
+
+type weather struct {
+	temperature float64 // in °C
+	sky         sky
+}
+
+// weatherClient asks Open-Meteo for the weather where I live. The answer is
+// shared by all sessions and refreshed at most every weatherRefresh.
+type weatherClient struct {
+	city   string
+	client *http.Client
+
+	// lat and lon are found from the city when they aren't configured, by
+	// the fetch in flight which is the only one using them.
+	lat, lon string
+
+	mu       sync.Mutex
+	current  *weather
+	fetched  time.Time
+	failing  bool
+	fetching chan struct{} // closed once the fetch in flight is done
+}
+
+// newWeatherClient returns nil if neither the coordinates nor the city is
+// configured, which hides the widget.
+func newWeatherClient(cfg Config) *weatherClient {
+	if cfg.WeatherLat == "" && cfg.WeatherCity == "" {
+		return nil
+	}
+	return &weatherClient{
+		city:   sanitize(cfg.WeatherCity, weatherMaxCity),
+		lat:    cfg.WeatherLat,
+		lon:    cfg.WeatherLon,
+		client: &http.Client{Timeout: weatherTimeout},
+	}
+}
+
+// weather returns the current weather, or nil if Open-Meteo can't be
+// reached. It's asked with ctx, the context of the session asking, so the
+// request is cancelled along with the session.
+func (c *weatherClient) weather(ctx context.Context) *weather {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Open-Meteo is called without holding c.mu, sessions asking meanwhile
+	// wait for the fetch in flight instead of starting another, and start
+	// their own if it was cancelled.
+	for c.fetching != nil {
+		wait := c.fetching
+		c.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			c.mu.Lock()
+			return nil
+		}
+		c.mu.Lock()
+	}
+	refresh := weatherRefresh
+	if c.failing {
+		refresh = weatherRetry
+	}
+	if time.Since(c.fetched) < refresh {
+		return c.current
+	}
+	done := make(chan struct{})
+	c.fetching = done
+	c.mu.Unlock()
+	current, err := c.fetch(ctx)
+	c.mu.Lock()
+	c.fetching = nil
+	close(done)
+	// The session leaving isn't Open-Meteo failing.
+	if ctx.Err() != nil {
+		return nil
+	}
+	c.fetched = time.Now()
+	// Log failures once rather than on every retry.
+	if err != nil && !c.failing {
+		log.Warn("Could not fetch the weather", "city", c.city, "error", err)
+	}
+	c.failing = err != nil
+	c.current = current
+	return c.current
+}
+
+// fetch returns the current weather, looking up the coordinates of the city
+// first if they aren't known yet. It's only called by the fetch in flight.
+func (c *weatherClient) fetch(ctx context.Context) (*weather, error) {
+	if c.lat == "" {
+		if err := c.geocode(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var resp struct {
+		Current struct {
+			Temperature *float64 `json:"temperature_2m"`
+			WeatherCode int      `json:"weather_code"`
+		} `json:"current"`
+	}
+	if err := c.get(ctx, fmt.Sprintf(weatherEndpoint, url.QueryEscape(c.lat), url.QueryEscape(c.lon)), &resp); err != nil {
+		return nil, err
+	}
+	if resp.Current.Temperature == nil {
+		return nil, errors.New("open-meteo: no current temperature")
+	}
+	return &weather{*resp.Current.Temperature, skyFor(resp.Current.WeatherCode)}, nil
+}
+
+// geocode finds the coordinates of the city. It's only called by the fetch
+// in flight.
+func (c *weatherClient) geocode(ctx context.Context) error {
+	var resp struct {
+		Results []struct {
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		} `json:"results"`
+	}
+	if err := c.get(ctx, fmt.Sprintf(geocodeEndpoint, url.QueryEscape(c.city)), &resp); err != nil {
+		return err
+	}
+	if len(resp.Results) == 0 {
+		return fmt.Errorf("open-meteo geocoding: no city called %q", c.city)
+	}
+	c.lat = strconv.FormatFloat(resp.Results[0].Latitude, 'f', -1, 64)
+	c.lon = strconv.FormatFloat(resp.Results[0].Longitude, 'f', -1, 64)
+	return nil
+}
+
+// get decodes the JSON answer of the Open-Meteo API at endpoint into v.
+func (c *weatherClient) get(ctx context.Context, endpoint string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("open-meteo: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode open-meteo: %w", err)
+	}
+	return nil
+}
+
+type weatherMsg struct{ weather *weather }
+
+func loadWeather(ctx context.Context, c *weatherClient) tea.Cmd {
+	return func() tea.Msg {
+		return weatherMsg{c.weather(ctx)}
+	}
+}
+
+// weatherTick asks c again every weatherRetry, which only fetches the weather
+// when it's due.
+func weatherTick(ctx context.Context, c *weatherClient) tea.Cmd {
+	return tea.Tick(weatherRetry, func(time.Time) tea.Msg {
+		return weatherMsg{c.weather(ctx)}
+	})
+}
+
+// weatherText renders the weather for the footer, or nothing if it's
+// unknown.
+func (m model) weatherText() string {
+	if m.weather == nil {
+		return ""
+	}
+	g := m.glyphs
+	s := fmt.Sprintf("%s%s %d%sC", g.dot, g.sky[m.weather.sky], int(math.Round(m.weather.temperature)), g.degree)
+	if city := m.weatherClient.city; city != "" {
+		s += " in " + city
+	}
+	return s
+}
+
+// parseCoordinates checks the latitude and longitude the weather is fetched
+// for, both or neither being set.
+func parseCoordinates(lat, lon string) error {
+	if lat == "" && lon == "" {
+		return nil
+	}
+	if lat == "" || lon == "" {
+		return errors.New("must set both the latitude and longitude")
+	}
+	if v, err := strconv.ParseFloat(lat, 64); err != nil || v < -90 || v > 90 {
+		return fmt.Errorf("invalid latitude %q: must be a number between -90 and 90", lat)
+	}
+	if v, err := strconv.ParseFloat(lon, 64); err != nil || v < -180 || v > 180 {
+		return fmt.Errorf("invalid longitude %q: must be a number between -180 and 180", lon)
+	}
+	return nil
+}
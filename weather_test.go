@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestWeatherFetchesOnceWithoutLocking(t *testing.T) {
+	transport := &blockingTransport{
+		body:    `{"current":{"temperature_2m":21.5,"weather_code":0}}`,
+		started: make(chan struct{}, 5),
+		release: make(chan struct{}),
+	}
+	c := newWeatherClient(Config{WeatherLat: "18.5", WeatherLon: "73.8"})
+	c.client = &http.Client{Transport: transport}
+
+	var wg sync.WaitGroup
+	results := make([]*weather, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.weather(context.Background())
+		}()
+	}
+	<-transport.started
+	// The lock is free while Open-Meteo is called.
+	c.mu.Lock()
+	c.mu.Unlock()
+	close(transport.release)
+	wg.Wait()
+
+	if n := transport.requests.Load(); n != 1 {
+		t.Errorf("%d requests to Open-Meteo, want 1", n)
+	}
+	for i, w := range results {
+		if w == nil || w.temperature != 21.5 || w.sky != skyClear {
+			t.Errorf("weather %d returned %v, want 21.5°C and clear", i, w)
+		}
+	}
+}
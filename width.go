@@ -0,0 +1,20 @@
+package main
+
+import "github.com/mattn/go-runewidth"
+
+// padRight pads s with spaces to width terminal cells. Unlike the padding of
+// fmt, which counts runes, it lines up columns holding wide characters like
+// CJK, measured the same way as lipgloss does.
+func padRight(s string, width int) string {
+	return runewidth.FillRight(s, width)
+}
+
+// setEastAsianWidth makes the ambiguous width characters, like some symbols
+// and box drawing ones, count as two cells as they do in most CJK locales.
+// It applies to every session, lipgloss measuring strings with the same
+// condition.
+func setEastAsianWidth(on bool) {
+	if on {
+		runewidth.DefaultCondition.EastAsianWidth = true
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestPadRight(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+	}{
+		{"ascii", "Go"},
+		{"cjk", "日本語"},
+		{"emoji", "🚀 Kotlin"},
+		{"combining", "cafe\u0301"},
+		{"full width", "ＧＯ"},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lipgloss.Width(padRight(tt.s, 12)); got != 12 {
+				t.Errorf("padRight(%q, 12) is %d cells wide, want 12", tt.s, got)
+			}
+		})
+	}
+}